@@ -13,8 +13,20 @@ import (
 func main() {
 	runtime.GOMAXPROCS(2)
 	version := flag.Bool("version", false, "Show version and exit.")
-	checkServers := flag.Bool("check-servers", false, "Check servers status.")
+	checkServers := flag.Bool("check-servers", false, "Check servers status, exit code is 1 if any server is unavailable, draining or has no compiler.")
 	checkCompiler := flag.String("compiler", "gcc", "Check if the compiler available on the servers.")
+	checkFormat := flag.String("format", "text", "Output format of the servers check: text or json.")
+	drainServer := flag.String("drain-server", "", "Ask the server <host:port> to stop accepting compilations and shut down, requires admin permission.")
+	serverCache := flag.String("server-cache", "", "Run the cache command on the servers: stats, list, evict or resize, requires admin permission.")
+	cacheServer := flag.String("server", "", "Server <host:port> for the cache command, all servers from POPCORN_SERVERS if empty.")
+	cacheOptions := client.CacheCommandOptions{}
+	flag.StringVar(&cacheOptions.Cache, "cache", "obj", "Cache for the cache command: obj or src.")
+	flag.StringVar(&cacheOptions.FileNamePattern, "name", "", "Shell pattern of file names for list and evict.")
+	flag.DurationVar(&cacheOptions.OlderThan, "older-than", 0, "Select entries which are not used for this time for list and evict.")
+	flag.StringVar(&cacheOptions.Key, "key", "", "Hex sha256 key of the entry for list and evict.")
+	flag.BoolVar(&cacheOptions.All, "all", false, "Allow evict without filters, the whole cache is cleared.")
+	flag.Int64Var(&cacheOptions.Limit, "limit", 100, "Maximum amount of listed entries, unlimited if zero.")
+	flag.Int64Var(&cacheOptions.SizeLimit, "size", -1, "New cache size limit in bytes for resize.")
 
 	flag.Parse()
 
@@ -24,12 +36,47 @@ func main() {
 	}
 
 	settings := client.ReadClientSettings()
-	if err := common.LoggerInit("popcorn-client", settings.LogFileName, settings.LogSeverity); err != nil {
+	if *checkServers && *checkFormat == "json" && len(settings.LogFileName) == 0 && len(settings.LogSink) == 0 {
+		// The logger writes to stdout by default, it would break the json output
+		settings.LogSink = common.LogSinkStderr
+	}
+	if err := common.LoggerInit("popcorn-client", common.LoggerSettings{
+		FileName:            settings.LogFileName,
+		Severity:            settings.LogSeverity,
+		Format:              settings.LogFormat,
+		Sink:                settings.LogSink,
+		ComponentSeverities: settings.LogLevels,
+	}); err != nil {
 		common.LogFatal("Can't init logger", err)
 	}
+	settings.LogWarnings()
 
 	if *checkServers {
-		client.CheckServers(settings, *checkCompiler)
+		healthy, err := client.CheckServers(settings, *checkCompiler, *checkFormat)
+		if err != nil {
+			common.LogFatal("Can't check servers:", err)
+		}
+		if !healthy {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if len(*drainServer) != 0 {
+		if err := client.DrainServer(settings, *drainServer); err != nil {
+			common.LogFatal("Can't drain server:", err)
+		}
+		os.Exit(0)
+	}
+
+	if len(*serverCache) != 0 {
+		servers := settings.Servers
+		if len(*cacheServer) != 0 {
+			servers = []string{*cacheServer}
+		}
+		if !client.ManageServersCache(settings, servers, *serverCache, &cacheOptions) {
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
@@ -6,6 +6,8 @@ import (
 	"net"
 	"os"
 	"path"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/AlexK0/popcorn/internal/common"
@@ -16,16 +18,14 @@ import (
 	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
 )
 
-func cleanupWorkingDir(workingDir string) bool {
-	oldWorkingDir := workingDir + ".old"
-
-	if err := os.RemoveAll(oldWorkingDir); err != nil {
+// cleanupWorkingDir removes sessions of the previous run, the file caches are kept.
+func cleanupWorkingDir(workingDir string, sessionsDir string) bool {
+	// Previous versions moved the whole working directory aside
+	if err := os.RemoveAll(workingDir + ".old"); err != nil {
 		common.LogWarning(err)
 	}
-	if _, err := os.Stat(workingDir); err == nil {
-		if err := os.Rename(workingDir, oldWorkingDir); err != nil {
-			common.LogWarning(err)
-		}
+	if err := os.RemoveAll(sessionsDir); err != nil {
+		common.LogWarning(err)
 	}
 	if err := os.MkdirAll(workingDir, os.ModePerm); err != nil {
 		common.LogError(err)
@@ -34,7 +34,35 @@ func cleanupWorkingDir(workingDir string) bool {
 	return true
 }
 
+func makeCacheStorage(cacheDir string, cacheLimit int64, compression bool, cacheURL string, contentAddressed bool, settings *server.Settings) (server.CacheStorage, error) {
+	if len(cacheURL) != 0 {
+		return server.MakeHTTPCacheStorage(cacheURL, settings.CacheURLLayout, contentAddressed, settings.CacheHTTPTimeout)
+	}
+	return server.MakeFileCache(cacheDir, cacheLimit, compression)
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(list string) []string {
+	result := make([]string, 0, 8)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 func main() {
+	common.HandleSandboxInit()
+
 	settings := &server.Settings{}
 
 	version := flag.Bool("version", false, "Show version and exit.")
@@ -43,9 +71,51 @@ func main() {
 	flag.StringVar(&settings.WorkingDir, "working-dir", "/tmp/popcorn-server", "Directory for saving and compiling incoming files.")
 	flag.StringVar(&settings.LogFileName, "log-filename", "", "Logger file.")
 	flag.StringVar(&settings.LogSeverity, "log-severity", common.WarningSeverity, "Logger severity level.")
+	flag.StringVar(&settings.LogFormat, "log-format", common.LogFormatText, "Logger format: text or json.")
+	flag.StringVar(&settings.LogSink, "log-sink", common.LogSinkFile, "Logger sink: file (stdout if -log-filename is empty), stderr, syslog or journald.")
+	flag.StringVar(&settings.LogLevels, "log-levels", "", "Severity levels of logger components, e.g. 'core=ERROR,cache=INFO'.")
 	flag.Int64Var(&settings.SrcCacheLimit, "src-cache-limit", 512*1024*1024, "Header and source cache limit in bytes.")
 	flag.Int64Var(&settings.ObjCacheLimit, "obj-cache-limit", 1024*1024*1024, "Compiled object cache limit in bytes.")
+	flag.BoolVar(&settings.SrcCacheCompression, "src-cache-compression", false, "Keep headers and sources in the local disk cache compressed with zstd.")
+	flag.BoolVar(&settings.ObjCacheCompression, "obj-cache-compression", false, "Keep compiled objects in the local disk cache compressed with zstd.")
+	flag.StringVar(&settings.SrcCacheURL, "src-cache-url", "", "Http cache service url for headers and sources instead of the local disk cache.")
+	flag.StringVar(&settings.ObjCacheURL, "obj-cache-url", "", "Http cache service url for compiled objects instead of the local disk cache.")
+	flag.StringVar(&settings.CacheURLLayout, "cache-url-layout", server.HTTPCacheLayoutBazel, "Layout of the http cache: flat, subdirs (ccache) or bazel (bazel-remote).")
+	flag.DurationVar(&settings.CacheHTTPTimeout, "cache-http-timeout", 10*time.Second, "Timeout of http cache requests.")
 	flag.StringVar(&settings.StatsdAddress, "statsd", "", "Statsd address.")
+	flag.StringVar(&settings.TraceDir, "trace-dir", "", "Directory for trace files in OTLP JSON format, enables tracing.")
+	flag.StringVar(&settings.TraceURL, "trace-url", "", "OTLP/HTTP collector url for traces, e.g. http://localhost:4318, enables tracing.")
+	flag.StringVar(&settings.MetricsAddress, "metrics-address", "", "Address <host:port> of the http server with Prometheus /metrics, disabled if empty.")
+	flag.BoolVar(&settings.StatusPage, "status-page", false, "Serve the status page on / and /status.json of the metrics http server, only on a loopback address.")
+	flag.BoolVar(&settings.Pprof, "pprof", false, "Serve the Go profiler on /debug/pprof/ of the metrics http server, only on a loopback address.")
+	flag.StringVar(&settings.TLSCertFile, "tls-cert", "", "TLS certificate file, enables TLS.")
+	flag.StringVar(&settings.TLSKeyFile, "tls-key", "", "TLS private key file.")
+	flag.StringVar(&settings.TLSClientCAFile, "tls-client-ca", "", "CA file for verifying client certificates, enables mutual TLS.")
+	flag.StringVar(&settings.AuthTokensFile, "auth-tokens-file", "", "File with '<token> <name> <permissions>' lines, enables authentication, requires TLS.")
+	flag.StringVar(&settings.AuthHMACKeyFile, "auth-hmac-key-file", "", "HMAC key file for signed tokens, enables authentication, requires TLS.")
+	flag.BoolVar(&settings.AllowUnauthenticatedAdmin, "allow-unauthenticated-admin", false, "Allow drain and cache admin calls from anyone if the authentication is disabled.")
+	allowedCompilers := flag.String("allowed-compilers", "", "Comma separated compilers (names from PATH or absolute paths) which clients can use, any if empty.")
+	deniedCompilerFlags := flag.String("denied-compiler-flags", strings.Join(server.DefaultDeniedCompilerFlags, ","), "Comma separated prefixes of denied compiler flags.")
+	allowedCompilerFlags := flag.String("allowed-compiler-flags", "", "Comma separated prefixes of compiler flags allowed despite the denied list.")
+	flag.BoolVar(&settings.UseSandbox, "sandbox", false, "Run compilers in user, mount, pid and network namespaces.")
+	sandboxReadOnlyDirs := flag.String("sandbox-ro-dirs", "/usr,/lib,/lib32,/lib64,/libx32,/bin,/sbin,/etc", "Comma separated toolchain and system directories available in the sandbox.")
+	flag.IntVar(&settings.SandboxUID, "sandbox-uid", 65534, "User id of compilers inside the sandbox.")
+	flag.IntVar(&settings.SandboxGID, "sandbox-gid", 65534, "Group id of compilers inside the sandbox.")
+	flag.IntVar(&settings.MaxParallelCompilations, "max-parallel-compilations", runtime.NumCPU(), "Maximum amount of concurrently running compilers.")
+	flag.DurationVar(&settings.CompilationTimeout, "compilation-timeout", 10*time.Minute, "Wall clock limit of a compilation, unlimited if zero.")
+	flag.DurationVar(&settings.CompilerCPUTimeLimit, "compiler-cpu-time-limit", 0, "CPU time limit of each compiler process, unlimited if zero.")
+	flag.Int64Var(&settings.CompilerMemoryLimit, "compiler-memory-limit", 0, "Memory limit of a compilation in bytes, unlimited if zero.")
+	flag.StringVar(&settings.CompilerCgroupDir, "compiler-cgroup-dir", "", "Delegated cgroup v2 directory, compilations get child cgroups with memory and cpu limits.")
+	flag.Float64Var(&settings.CompilerCgroupCPUs, "compiler-cgroup-cpus", 0, "CPU limit of a compilation in cores, requires cgroup, unlimited if zero.")
+	flag.DurationVar(&settings.CompileSourceWaitTimeout, "compile-source-wait-timeout", 30*time.Second, "Close sessions whose compilation result isn't requested by the client in this time, disabled if zero.")
+	flag.DurationVar(&settings.SessionIdleTTL, "session-idle-ttl", 10*time.Minute, "Close sessions without client activity for this time, disabled if zero.")
+	flag.DurationVar(&settings.DrainTimeout, "drain-timeout", 5*time.Minute, "Time to wait for active sessions after SIGTERM or drain request before stopping, unlimited if zero.")
+	peers := flag.String("peers", "", "Comma separated <host:port> of cluster servers sharing object caches, may include this server.")
+	flag.StringVar(&settings.AdvertiseAddress, "advertise-address", "", "Address <host:port> of this server in the peers list.")
+	flag.DurationVar(&settings.PeerTimeout, "peer-timeout", 2*time.Second, "Timeout of fetching an object from a peer.")
+	flag.StringVar(&settings.PeerTLSCAFile, "peer-tls-ca", "", "CA file for verifying peer certificates, enables TLS to peers; -tls-cert is used as the client certificate.")
+	flag.StringVar(&settings.PeerAuthTokenFile, "peer-auth-token-file", "", "File with a token for peers, requires the peer permission.")
+	issueToken := flag.String("issue-auth-token", "", "Print a token signed by the HMAC key and exit, format is <name>:<permissions>:<ttl>.")
 
 	flag.Parse()
 
@@ -54,11 +124,50 @@ func main() {
 		os.Exit(0)
 	}
 
-	if !cleanupWorkingDir(settings.WorkingDir) {
+	settings.AllowedCompilers = splitList(*allowedCompilers)
+	settings.DeniedCompilerFlags = splitList(*deniedCompilerFlags)
+	settings.AllowedCompilerFlags = splitList(*allowedCompilerFlags)
+	settings.SandboxReadOnlyDirs = splitList(*sandboxReadOnlyDirs)
+	settings.Peers = splitList(*peers)
+
+	authenticator, err := server.MakeAuthenticator(settings.AuthTokensFile, settings.AuthHMACKeyFile)
+	if err != nil {
+		common.LogFatal("Failed to init authentication:", err)
+	}
+
+	if len(*issueToken) != 0 {
+		tokenParts := strings.Split(*issueToken, ":")
+		if len(tokenParts) != 3 {
+			common.LogFatal("Token description <name>:<permissions>:<ttl> is expected")
+		}
+		permissions, err := server.ParsePermissions(tokenParts[1])
+		if err != nil {
+			common.LogFatal("Bad token permissions:", err)
+		}
+		ttl, err := time.ParseDuration(tokenParts[2])
+		if err != nil {
+			common.LogFatal("Bad token ttl:", err)
+		}
+		token, err := authenticator.IssueToken(tokenParts[0], permissions, ttl)
+		if err != nil {
+			common.LogFatal("Can't issue token:", err)
+		}
+		fmt.Println(token)
+		os.Exit(0)
+	}
+
+	sessionsDir := path.Join(settings.WorkingDir, "sessions")
+	if !cleanupWorkingDir(settings.WorkingDir, sessionsDir) {
 		common.LogFatal("Can't create working directory", settings.WorkingDir)
 	}
 
-	if err := common.LoggerInit("popcorn-server", settings.LogFileName, settings.LogSeverity); err != nil {
+	if err := common.LoggerInit("popcorn-server", common.LoggerSettings{
+		FileName:            settings.LogFileName,
+		Severity:            settings.LogSeverity,
+		Format:              settings.LogFormat,
+		Sink:                settings.LogSink,
+		ComponentSeverities: settings.LogLevels,
+	}); err != nil {
 		common.LogFatal("Can't init logger", err)
 	}
 
@@ -74,34 +183,123 @@ func main() {
 		common.LogFatal("Failed to listen:", err)
 	}
 
-	srcCache, err := server.MakeFileCache(path.Join(settings.WorkingDir, "src-cache"), settings.SrcCacheLimit)
+	srcCache, err := makeCacheStorage(path.Join(settings.WorkingDir, "src-cache"), settings.SrcCacheLimit, settings.SrcCacheCompression, settings.SrcCacheURL, true, settings)
 	if err != nil {
 		common.LogFatal("Failed to init src file cache:", err)
 	}
 
-	objCache, err := server.MakeFileCache(path.Join(settings.WorkingDir, "obj-cache"), settings.ObjCacheLimit)
+	objCache, err := makeCacheStorage(path.Join(settings.WorkingDir, "obj-cache"), settings.ObjCacheLimit, settings.ObjCacheCompression, settings.ObjCacheURL, false, settings)
 	if err != nil {
-		common.LogFatal("Failed to init src file cache:", err)
+		common.LogFatal("Failed to init obj file cache:", err)
+	}
+
+	var sandbox *common.Sandbox
+	if settings.UseSandbox {
+		sandbox, err = common.MakeSandbox(path.Join(settings.WorkingDir, "sandbox-root"), settings.SandboxReadOnlyDirs, settings.SandboxUID, settings.SandboxGID)
+		if err != nil {
+			common.LogFatal("Failed to init sandbox:", err)
+		}
+	}
+
+	transportCredentials, err := server.MakeTransportCredentials(settings.TLSCertFile, settings.TLSKeyFile, settings.TLSClientCAFile)
+	if err != nil {
+		common.LogFatal("Failed to init TLS:", err)
+	}
+	if authenticator != nil && transportCredentials == nil {
+		common.LogFatal("Token authentication requires TLS, bearer tokens can't be accepted over plaintext connections")
+	}
+
+	var serverOptions []grpc.ServerOption
+	if transportCredentials != nil {
+		serverOptions = append(serverOptions, grpc.Creds(transportCredentials))
+	}
+	if authenticator != nil {
+		serverOptions = append(serverOptions,
+			grpc.UnaryInterceptor(authenticator.UnaryInterceptor),
+			grpc.StreamInterceptor(authenticator.StreamInterceptor))
+	}
+
+	if len(settings.CompilerCgroupDir) != 0 && !common.IsCgroupV2Available(settings.CompilerCgroupDir) {
+		common.LogWarning("Cgroup v2 is not available in", settings.CompilerCgroupDir, "fall back to rlimits")
+		settings.CompilerCgroupDir = ""
+	}
+
+	var objectCachePeers *server.ObjectCachePeers
+	if len(settings.Peers) != 0 {
+		if authenticator == nil || len(settings.PeerAuthTokenFile) == 0 {
+			common.LogFatal("Object cache peers require authentication and -peer-auth-token-file with the peer permission")
+		}
+		peerDialOptions, err := server.MakePeerDialOptions(settings.PeerTLSCAFile, settings.TLSCertFile, settings.TLSKeyFile, settings.PeerAuthTokenFile)
+		if err != nil {
+			common.LogFatal("Failed to init peers:", err)
+		}
+		if !containsString(settings.Peers, settings.AdvertiseAddress) {
+			common.LogWarning("Advertise address", settings.AdvertiseAddress, "is not in the peers list, the server may ask itself")
+		}
+		objectCachePeers = server.MakeObjectCachePeers(settings.Peers, settings.AdvertiseAddress, settings.PeerTimeout, peerDialOptions)
+		defer objectCachePeers.Close()
+	}
+
+	grpcServer := grpc.NewServer(serverOptions...)
+	serverName := settings.AdvertiseAddress
+	if len(serverName) == 0 {
+		hostname, _ := os.Hostname()
+		serverName = fmt.Sprintf("%s:%d", hostname, settings.Port)
 	}
 
-	grpcServer := grpc.NewServer()
+	common.SetLogFields(common.LogField{Key: common.LogFieldServer, Value: serverName})
+
 	compilationServer := &server.CompilationServer{
 		StartTime:   time.Now(),
-		SessionsDir: path.Join(settings.WorkingDir, "sessions"),
+		ServerName:  serverName,
+		SessionsDir: sessionsDir,
 		GRPCServer:  grpcServer,
 
+		Authenticator:             authenticator,
+		AllowUnauthenticatedAdmin: settings.AllowUnauthenticatedAdmin,
+		CompilerPolicy:            server.MakeCompilerPolicy(settings.AllowedCompilers, settings.DeniedCompilerFlags, settings.AllowedCompilerFlags),
+
+		CompilerIdentities: server.MakeCompilerIdentities(),
+
 		RemoteClients:  server.MakeClients(),
 		UploadingFiles: server.MakeTransferringFiles(),
 		SystemHeaders:  server.MakeSystemHeaderCache(),
 		SrcFileCache:   srcCache,
 		ObjFileCache:   objCache,
 
-		ActiveSessions: server.MakeSessions(),
+		ObjectCachePeers: objectCachePeers,
 
-		Stats: serverStats,
+		ActiveSessions:   server.MakeSessions(),
+		CompilationQueue: server.MakeCompilationQueue(settings.MaxParallelCompilations),
+
+		Sandbox: sandbox,
+		CompilerLimits: server.CompilerLimits{
+			Timeout:         settings.CompilationTimeout,
+			CPUTime:         settings.CompilerCPUTimeLimit,
+			Memory:          settings.CompilerMemoryLimit,
+			CgroupParentDir: settings.CompilerCgroupDir,
+			CgroupCPUs:      settings.CompilerCgroupCPUs,
+		},
+		CompileSourceWaitTimeout: settings.CompileSourceWaitTimeout,
+		SessionIdleTTL:           settings.SessionIdleTTL,
+		DrainTimeout:             settings.DrainTimeout,
+
+		Stats:          serverStats,
+		RecentFailures: server.MakeCompilationFailures(20),
+		Tracer:         common.MakeTracer("popcorn-server", settings.TraceDir, settings.TraceURL),
 	}
 	pb.RegisterCompilationServiceServer(grpcServer, compilationServer)
 
+	if len(settings.MetricsAddress) != 0 {
+		httpServer := server.StartHTTPServer(settings.MetricsAddress, compilationServer, server.HTTPServerOptions{
+			StatusPage: settings.StatusPage,
+			Pprof:      settings.Pprof,
+		})
+		defer httpServer.Close()
+	} else if settings.StatusPage || settings.Pprof {
+		common.LogWarning("Status page and pprof require -metrics-address")
+	}
+
 	cron := server.Cron{Server: compilationServer}
 	cron.Start()
 
@@ -111,6 +309,14 @@ func main() {
 
 	cron.Stop()
 	grpcServer.Stop()
+	for _, cache := range []server.CacheStorage{srcCache, objCache} {
+		if err := cache.Close(); err != nil {
+			common.LogError("Can't close cache:", err)
+		}
+	}
+	if err := compilationServer.Tracer.Flush(); err != nil {
+		common.LogError("Can't export traces:", err)
+	}
 	serverStats.Close()
 	lis.Close()
 }
@@ -25,6 +25,115 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+type CompilationPriority int32
+
+const (
+	CompilationPriority_PRIORITY_NORMAL      CompilationPriority = 0
+	CompilationPriority_PRIORITY_INTERACTIVE CompilationPriority = 1
+	CompilationPriority_PRIORITY_BATCH       CompilationPriority = 2
+)
+
+// Enum value maps for CompilationPriority.
+var (
+	CompilationPriority_name = map[int32]string{
+		0: "PRIORITY_NORMAL",
+		1: "PRIORITY_INTERACTIVE",
+		2: "PRIORITY_BATCH",
+	}
+	CompilationPriority_value = map[string]int32{
+		"PRIORITY_NORMAL":      0,
+		"PRIORITY_INTERACTIVE": 1,
+		"PRIORITY_BATCH":       2,
+	}
+)
+
+func (x CompilationPriority) Enum() *CompilationPriority {
+	p := new(CompilationPriority)
+	*p = x
+	return p
+}
+
+func (x CompilationPriority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CompilationPriority) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_v1_compilation_server_proto_enumTypes[0].Descriptor()
+}
+
+func (CompilationPriority) Type() protoreflect.EnumType {
+	return &file_api_proto_v1_compilation_server_proto_enumTypes[0]
+}
+
+func (x CompilationPriority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CompilationPriority.Descriptor instead.
+func (CompilationPriority) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{0}
+}
+
+type ObjectCacheMode int32
+
+const (
+	// OBJ_CACHE_DEFAULT follows UseObjectCache for older clients
+	ObjectCacheMode_OBJ_CACHE_DEFAULT    ObjectCacheMode = 0
+	ObjectCacheMode_OBJ_CACHE_OFF        ObjectCacheMode = 1
+	ObjectCacheMode_OBJ_CACHE_READ_WRITE ObjectCacheMode = 2
+	ObjectCacheMode_OBJ_CACHE_READ_ONLY  ObjectCacheMode = 3
+	ObjectCacheMode_OBJ_CACHE_WRITE_ONLY ObjectCacheMode = 4
+	// OBJ_CACHE_RECACHE compiles and overwrites the cached object
+	ObjectCacheMode_OBJ_CACHE_RECACHE ObjectCacheMode = 5
+)
+
+// Enum value maps for ObjectCacheMode.
+var (
+	ObjectCacheMode_name = map[int32]string{
+		0: "OBJ_CACHE_DEFAULT",
+		1: "OBJ_CACHE_OFF",
+		2: "OBJ_CACHE_READ_WRITE",
+		3: "OBJ_CACHE_READ_ONLY",
+		4: "OBJ_CACHE_WRITE_ONLY",
+		5: "OBJ_CACHE_RECACHE",
+	}
+	ObjectCacheMode_value = map[string]int32{
+		"OBJ_CACHE_DEFAULT":    0,
+		"OBJ_CACHE_OFF":        1,
+		"OBJ_CACHE_READ_WRITE": 2,
+		"OBJ_CACHE_READ_ONLY":  3,
+		"OBJ_CACHE_WRITE_ONLY": 4,
+		"OBJ_CACHE_RECACHE":    5,
+	}
+)
+
+func (x ObjectCacheMode) Enum() *ObjectCacheMode {
+	p := new(ObjectCacheMode)
+	*p = x
+	return p
+}
+
+func (x ObjectCacheMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ObjectCacheMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_v1_compilation_server_proto_enumTypes[1].Descriptor()
+}
+
+func (ObjectCacheMode) Type() protoreflect.EnumType {
+	return &file_api_proto_v1_compilation_server_proto_enumTypes[1]
+}
+
+func (x ObjectCacheMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ObjectCacheMode.Descriptor instead.
+func (ObjectCacheMode) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{1}
+}
+
 type RequiredStatus int32
 
 const (
@@ -58,11 +167,11 @@ func (x RequiredStatus) String() string {
 }
 
 func (RequiredStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_proto_v1_compilation_server_proto_enumTypes[0].Descriptor()
+	return file_api_proto_v1_compilation_server_proto_enumTypes[2].Descriptor()
 }
 
 func (RequiredStatus) Type() protoreflect.EnumType {
-	return &file_api_proto_v1_compilation_server_proto_enumTypes[0]
+	return &file_api_proto_v1_compilation_server_proto_enumTypes[2]
 }
 
 func (x RequiredStatus) Number() protoreflect.EnumNumber {
@@ -71,7 +180,106 @@ func (x RequiredStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RequiredStatus.Descriptor instead.
 func (RequiredStatus) EnumDescriptor() ([]byte, []int) {
-	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{0}
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{2}
+}
+
+type ObjectCacheResult int32
+
+const (
+	// OBJ_CACHE_RESULT_SKIPPED means the object cache isn't read
+	ObjectCacheResult_OBJ_CACHE_RESULT_SKIPPED  ObjectCacheResult = 0
+	ObjectCacheResult_OBJ_CACHE_RESULT_MISS     ObjectCacheResult = 1
+	ObjectCacheResult_OBJ_CACHE_RESULT_HIT      ObjectCacheResult = 2
+	ObjectCacheResult_OBJ_CACHE_RESULT_PEER_HIT ObjectCacheResult = 3
+)
+
+// Enum value maps for ObjectCacheResult.
+var (
+	ObjectCacheResult_name = map[int32]string{
+		0: "OBJ_CACHE_RESULT_SKIPPED",
+		1: "OBJ_CACHE_RESULT_MISS",
+		2: "OBJ_CACHE_RESULT_HIT",
+		3: "OBJ_CACHE_RESULT_PEER_HIT",
+	}
+	ObjectCacheResult_value = map[string]int32{
+		"OBJ_CACHE_RESULT_SKIPPED":  0,
+		"OBJ_CACHE_RESULT_MISS":     1,
+		"OBJ_CACHE_RESULT_HIT":      2,
+		"OBJ_CACHE_RESULT_PEER_HIT": 3,
+	}
+)
+
+func (x ObjectCacheResult) Enum() *ObjectCacheResult {
+	p := new(ObjectCacheResult)
+	*p = x
+	return p
+}
+
+func (x ObjectCacheResult) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ObjectCacheResult) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_v1_compilation_server_proto_enumTypes[3].Descriptor()
+}
+
+func (ObjectCacheResult) Type() protoreflect.EnumType {
+	return &file_api_proto_v1_compilation_server_proto_enumTypes[3]
+}
+
+func (x ObjectCacheResult) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ObjectCacheResult.Descriptor instead.
+func (ObjectCacheResult) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{3}
+}
+
+type CacheType int32
+
+const (
+	CacheType_CACHE_OBJ CacheType = 0
+	CacheType_CACHE_SRC CacheType = 1
+)
+
+// Enum value maps for CacheType.
+var (
+	CacheType_name = map[int32]string{
+		0: "CACHE_OBJ",
+		1: "CACHE_SRC",
+	}
+	CacheType_value = map[string]int32{
+		"CACHE_OBJ": 0,
+		"CACHE_SRC": 1,
+	}
+)
+
+func (x CacheType) Enum() *CacheType {
+	p := new(CacheType)
+	*p = x
+	return p
+}
+
+func (x CacheType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CacheType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_v1_compilation_server_proto_enumTypes[4].Descriptor()
+}
+
+func (CacheType) Type() protoreflect.EnumType {
+	return &file_api_proto_v1_compilation_server_proto_enumTypes[4]
+}
+
+func (x CacheType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CacheType.Descriptor instead.
+func (CacheType) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{4}
 }
 
 type SHA256Message struct {
@@ -213,13 +421,15 @@ type StartCompilationSessionRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ClientID       *SHA256Message  `protobuf:"bytes,1,opt,name=ClientID,proto3" json:"ClientID,omitempty"`
-	ClientUserName string          `protobuf:"bytes,2,opt,name=ClientUserName,proto3" json:"ClientUserName,omitempty"`
-	SourceFilePath string          `protobuf:"bytes,3,opt,name=SourceFilePath,proto3" json:"SourceFilePath,omitempty"`
-	Compiler       string          `protobuf:"bytes,4,opt,name=Compiler,proto3" json:"Compiler,omitempty"`
-	CompilerArgs   []string        `protobuf:"bytes,5,rep,name=CompilerArgs,proto3" json:"CompilerArgs,omitempty"`
-	RequiredFiles  []*FileMetadata `protobuf:"bytes,6,rep,name=RequiredFiles,proto3" json:"RequiredFiles,omitempty"`
-	UseObjectCache bool            `protobuf:"varint,7,opt,name=UseObjectCache,proto3" json:"UseObjectCache,omitempty"`
+	ClientID        *SHA256Message      `protobuf:"bytes,1,opt,name=ClientID,proto3" json:"ClientID,omitempty"`
+	ClientUserName  string              `protobuf:"bytes,2,opt,name=ClientUserName,proto3" json:"ClientUserName,omitempty"`
+	SourceFilePath  string              `protobuf:"bytes,3,opt,name=SourceFilePath,proto3" json:"SourceFilePath,omitempty"`
+	Compiler        string              `protobuf:"bytes,4,opt,name=Compiler,proto3" json:"Compiler,omitempty"`
+	CompilerArgs    []string            `protobuf:"bytes,5,rep,name=CompilerArgs,proto3" json:"CompilerArgs,omitempty"`
+	RequiredFiles   []*FileMetadata     `protobuf:"bytes,6,rep,name=RequiredFiles,proto3" json:"RequiredFiles,omitempty"`
+	UseObjectCache  bool                `protobuf:"varint,7,opt,name=UseObjectCache,proto3" json:"UseObjectCache,omitempty"`
+	Priority        CompilationPriority `protobuf:"varint,8,opt,name=Priority,proto3,enum=popcorn.CompilationPriority" json:"Priority,omitempty"`
+	ObjectCacheMode ObjectCacheMode     `protobuf:"varint,9,opt,name=ObjectCacheMode,proto3,enum=popcorn.ObjectCacheMode" json:"ObjectCacheMode,omitempty"`
 }
 
 func (x *StartCompilationSessionRequest) Reset() {
@@ -303,6 +513,20 @@ func (x *StartCompilationSessionRequest) GetUseObjectCache() bool {
 	return false
 }
 
+func (x *StartCompilationSessionRequest) GetPriority() CompilationPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return CompilationPriority_PRIORITY_NORMAL
+}
+
+func (x *StartCompilationSessionRequest) GetObjectCacheMode() ObjectCacheMode {
+	if x != nil {
+		return x.ObjectCacheMode
+	}
+	return ObjectCacheMode_OBJ_CACHE_DEFAULT
+}
+
 type RequiredFile struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -812,10 +1036,23 @@ type StatusReply struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ServerVersion   string   `protobuf:"bytes,1,opt,name=ServerVersion,proto3" json:"ServerVersion,omitempty"`
-	ServerArgs      []string `protobuf:"bytes,2,rep,name=ServerArgs,proto3" json:"ServerArgs,omitempty"`
-	ServerUptime    int64    `protobuf:"varint,3,opt,name=ServerUptime,proto3" json:"ServerUptime,omitempty"`
-	CompilerVersion string   `protobuf:"bytes,4,opt,name=CompilerVersion,proto3" json:"CompilerVersion,omitempty"`
+	ServerVersion           string   `protobuf:"bytes,1,opt,name=ServerVersion,proto3" json:"ServerVersion,omitempty"`
+	ServerArgs              []string `protobuf:"bytes,2,rep,name=ServerArgs,proto3" json:"ServerArgs,omitempty"`
+	ServerUptime            int64    `protobuf:"varint,3,opt,name=ServerUptime,proto3" json:"ServerUptime,omitempty"`
+	CompilerVersion         string   `protobuf:"bytes,4,opt,name=CompilerVersion,proto3" json:"CompilerVersion,omitempty"`
+	RunningCompilations     int64    `protobuf:"varint,5,opt,name=RunningCompilations,proto3" json:"RunningCompilations,omitempty"`
+	QueuedCompilations      int64    `protobuf:"varint,6,opt,name=QueuedCompilations,proto3" json:"QueuedCompilations,omitempty"`
+	MaxParallelCompilations int64    `protobuf:"varint,7,opt,name=MaxParallelCompilations,proto3" json:"MaxParallelCompilations,omitempty"`
+	Draining                bool     `protobuf:"varint,8,opt,name=Draining,proto3" json:"Draining,omitempty"`
+	ServerName              string   `protobuf:"bytes,9,opt,name=ServerName,proto3" json:"ServerName,omitempty"`
+	CPUCount                int32    `protobuf:"varint,10,opt,name=CPUCount,proto3" json:"CPUCount,omitempty"`
+	// LoadAverage is 1, 5 and 15 minutes load average of the server host
+	LoadAverage    []float64       `protobuf:"fixed64,11,rep,packed,name=LoadAverage,proto3" json:"LoadAverage,omitempty"`
+	ActiveSessions int64           `protobuf:"varint,12,opt,name=ActiveSessions,proto3" json:"ActiveSessions,omitempty"`
+	Caches         []*CacheStats   `protobuf:"bytes,13,rep,name=Caches,proto3" json:"Caches,omitempty"`
+	Compilers      []*CompilerInfo `protobuf:"bytes,14,rep,name=Compilers,proto3" json:"Compilers,omitempty"`
+	// Capabilities are the protocol features supported by the server
+	Capabilities []string `protobuf:"bytes,15,rep,name=Capabilities,proto3" json:"Capabilities,omitempty"`
 }
 
 func (x *StatusReply) Reset() {
@@ -878,18 +1115,94 @@ func (x *StatusReply) GetCompilerVersion() string {
 	return ""
 }
 
-type TransferFileRequest_StreamHeader struct {
+func (x *StatusReply) GetRunningCompilations() int64 {
+	if x != nil {
+		return x.RunningCompilations
+	}
+	return 0
+}
+
+func (x *StatusReply) GetQueuedCompilations() int64 {
+	if x != nil {
+		return x.QueuedCompilations
+	}
+	return 0
+}
+
+func (x *StatusReply) GetMaxParallelCompilations() int64 {
+	if x != nil {
+		return x.MaxParallelCompilations
+	}
+	return 0
+}
+
+func (x *StatusReply) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *StatusReply) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *StatusReply) GetCPUCount() int32 {
+	if x != nil {
+		return x.CPUCount
+	}
+	return 0
+}
+
+func (x *StatusReply) GetLoadAverage() []float64 {
+	if x != nil {
+		return x.LoadAverage
+	}
+	return nil
+}
+
+func (x *StatusReply) GetActiveSessions() int64 {
+	if x != nil {
+		return x.ActiveSessions
+	}
+	return 0
+}
+
+func (x *StatusReply) GetCaches() []*CacheStats {
+	if x != nil {
+		return x.Caches
+	}
+	return nil
+}
+
+func (x *StatusReply) GetCompilers() []*CompilerInfo {
+	if x != nil {
+		return x.Compilers
+	}
+	return nil
+}
+
+func (x *StatusReply) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type CompilerInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SessionID  uint64         `protobuf:"varint,1,opt,name=SessionID,proto3" json:"SessionID,omitempty"`
-	FileIndex  uint32         `protobuf:"varint,2,opt,name=FileIndex,proto3" json:"FileIndex,omitempty"`
-	FileSHA256 *SHA256Message `protobuf:"bytes,3,opt,name=FileSHA256,proto3" json:"FileSHA256,omitempty"`
+	Compiler string `protobuf:"bytes,1,opt,name=Compiler,proto3" json:"Compiler,omitempty"`
+	Version  string `protobuf:"bytes,2,opt,name=Version,proto3" json:"Version,omitempty"`
 }
 
-func (x *TransferFileRequest_StreamHeader) Reset() {
-	*x = TransferFileRequest_StreamHeader{}
+func (x *CompilerInfo) Reset() {
+	*x = CompilerInfo{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -897,13 +1210,13 @@ func (x *TransferFileRequest_StreamHeader) Reset() {
 	}
 }
 
-func (x *TransferFileRequest_StreamHeader) String() string {
+func (x *CompilerInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TransferFileRequest_StreamHeader) ProtoMessage() {}
+func (*CompilerInfo) ProtoMessage() {}
 
-func (x *TransferFileRequest_StreamHeader) ProtoReflect() protoreflect.Message {
+func (x *CompilerInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -915,59 +1228,88 @@ func (x *TransferFileRequest_StreamHeader) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TransferFileRequest_StreamHeader.ProtoReflect.Descriptor instead.
-func (*TransferFileRequest_StreamHeader) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{5, 0}
+// Deprecated: Use CompilerInfo.ProtoReflect.Descriptor instead.
+func (*CompilerInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *TransferFileRequest_StreamHeader) GetSessionID() uint64 {
+func (x *CompilerInfo) GetCompiler() string {
 	if x != nil {
-		return x.SessionID
+		return x.Compiler
 	}
-	return 0
+	return ""
 }
 
-func (x *TransferFileRequest_StreamHeader) GetFileIndex() uint32 {
+func (x *CompilerInfo) GetVersion() string {
 	if x != nil {
-		return x.FileIndex
+		return x.Version
 	}
-	return 0
+	return ""
 }
 
-func (x *TransferFileRequest_StreamHeader) GetFileSHA256() *SHA256Message {
-	if x != nil {
-		return x.FileSHA256
+type DrainRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return nil
 }
 
-type CompileSourceReply_StreamEpilogue struct {
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{14}
+}
+
+type DrainReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CompilerRetCode int32  `protobuf:"varint,1,opt,name=CompilerRetCode,proto3" json:"CompilerRetCode,omitempty"`
-	CompilerStdout  []byte `protobuf:"bytes,2,opt,name=CompilerStdout,proto3" json:"CompilerStdout,omitempty"`
-	CompilerStderr  []byte `protobuf:"bytes,3,opt,name=CompilerStderr,proto3" json:"CompilerStderr,omitempty"`
+	ActiveSessions int64 `protobuf:"varint,1,opt,name=ActiveSessions,proto3" json:"ActiveSessions,omitempty"`
 }
 
-func (x *CompileSourceReply_StreamEpilogue) Reset() {
-	*x = CompileSourceReply_StreamEpilogue{}
+func (x *DrainReply) Reset() {
+	*x = DrainReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[14]
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CompileSourceReply_StreamEpilogue) String() string {
+func (x *DrainReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompileSourceReply_StreamEpilogue) ProtoMessage() {}
+func (*DrainReply) ProtoMessage() {}
 
-func (x *CompileSourceReply_StreamEpilogue) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[14]
+func (x *DrainReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -978,37 +1320,1182 @@ func (x *CompileSourceReply_StreamEpilogue) ProtoReflect() protoreflect.Message
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompileSourceReply_StreamEpilogue.ProtoReflect.Descriptor instead.
-func (*CompileSourceReply_StreamEpilogue) Descriptor() ([]byte, []int) {
-	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{8, 0}
+// Deprecated: Use DrainReply.ProtoReflect.Descriptor instead.
+func (*DrainReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *CompileSourceReply_StreamEpilogue) GetCompilerRetCode() int32 {
+func (x *DrainReply) GetActiveSessions() int64 {
 	if x != nil {
-		return x.CompilerRetCode
+		return x.ActiveSessions
 	}
 	return 0
 }
 
-func (x *CompileSourceReply_StreamEpilogue) GetCompilerStdout() []byte {
-	if x != nil {
-		return x.CompilerStdout
+type CacheStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cache CacheType `protobuf:"varint,1,opt,name=Cache,proto3,enum=popcorn.CacheType" json:"Cache,omitempty"`
+	// Remote caches are http services, only the server side counters are known
+	Remote        bool  `protobuf:"varint,2,opt,name=Remote,proto3" json:"Remote,omitempty"`
+	FilesCount    int64 `protobuf:"varint,3,opt,name=FilesCount,proto3" json:"FilesCount,omitempty"`
+	BytesOnDisk   int64 `protobuf:"varint,4,opt,name=BytesOnDisk,proto3" json:"BytesOnDisk,omitempty"`
+	OriginalBytes int64 `protobuf:"varint,5,opt,name=OriginalBytes,proto3" json:"OriginalBytes,omitempty"`
+	PurgedFiles   int64 `protobuf:"varint,6,opt,name=PurgedFiles,proto3" json:"PurgedFiles,omitempty"`
+	SizeLimit     int64 `protobuf:"varint,7,opt,name=SizeLimit,proto3" json:"SizeLimit,omitempty"`
+	// Hits and Misses are counted since the server start
+	Hits   int64 `protobuf:"varint,8,opt,name=Hits,proto3" json:"Hits,omitempty"`
+	Misses int64 `protobuf:"varint,9,opt,name=Misses,proto3" json:"Misses,omitempty"`
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return nil
 }
 
-func (x *CompileSourceReply_StreamEpilogue) GetCompilerStderr() []byte {
-	if x != nil {
-		return x.CompilerStderr
-	}
-	return nil
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var File_api_proto_v1_compilation_server_proto protoreflect.FileDescriptor
+func (*CacheStats) ProtoMessage() {}
 
-var file_api_proto_v1_compilation_server_proto_rawDesc = []byte{
-	0x0a, 0x25, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x63,
-	0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65,
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CacheStats) GetCache() CacheType {
+	if x != nil {
+		return x.Cache
+	}
+	return CacheType_CACHE_OBJ
+}
+
+func (x *CacheStats) GetRemote() bool {
+	if x != nil {
+		return x.Remote
+	}
+	return false
+}
+
+func (x *CacheStats) GetFilesCount() int64 {
+	if x != nil {
+		return x.FilesCount
+	}
+	return 0
+}
+
+func (x *CacheStats) GetBytesOnDisk() int64 {
+	if x != nil {
+		return x.BytesOnDisk
+	}
+	return 0
+}
+
+func (x *CacheStats) GetOriginalBytes() int64 {
+	if x != nil {
+		return x.OriginalBytes
+	}
+	return 0
+}
+
+func (x *CacheStats) GetPurgedFiles() int64 {
+	if x != nil {
+		return x.PurgedFiles
+	}
+	return 0
+}
+
+func (x *CacheStats) GetSizeLimit() int64 {
+	if x != nil {
+		return x.SizeLimit
+	}
+	return 0
+}
+
+func (x *CacheStats) GetHits() int64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() int64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+type GetCacheStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetCacheStatsRequest) Reset() {
+	*x = GetCacheStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCacheStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheStatsRequest) ProtoMessage() {}
+
+func (x *GetCacheStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetCacheStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{17}
+}
+
+type GetCacheStatsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Caches []*CacheStats `protobuf:"bytes,1,rep,name=Caches,proto3" json:"Caches,omitempty"`
+}
+
+func (x *GetCacheStatsReply) Reset() {
+	*x = GetCacheStatsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCacheStatsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheStatsReply) ProtoMessage() {}
+
+func (x *GetCacheStatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheStatsReply.ProtoReflect.Descriptor instead.
+func (*GetCacheStatsReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetCacheStatsReply) GetCaches() []*CacheStats {
+	if x != nil {
+		return x.Caches
+	}
+	return nil
+}
+
+type CacheEntriesFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cache CacheType `protobuf:"varint,1,opt,name=Cache,proto3,enum=popcorn.CacheType" json:"Cache,omitempty"`
+	// FileNamePattern is a shell pattern of file names, any name if empty
+	FileNamePattern string `protobuf:"bytes,2,opt,name=FileNamePattern,proto3" json:"FileNamePattern,omitempty"`
+	// MinAge in nanoseconds selects entries which are not used for this time
+	MinAge int64          `protobuf:"varint,3,opt,name=MinAge,proto3" json:"MinAge,omitempty"`
+	Key    *SHA256Message `protobuf:"bytes,4,opt,name=Key,proto3" json:"Key,omitempty"`
+}
+
+func (x *CacheEntriesFilter) Reset() {
+	*x = CacheEntriesFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheEntriesFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheEntriesFilter) ProtoMessage() {}
+
+func (x *CacheEntriesFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheEntriesFilter.ProtoReflect.Descriptor instead.
+func (*CacheEntriesFilter) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CacheEntriesFilter) GetCache() CacheType {
+	if x != nil {
+		return x.Cache
+	}
+	return CacheType_CACHE_OBJ
+}
+
+func (x *CacheEntriesFilter) GetFileNamePattern() string {
+	if x != nil {
+		return x.FileNamePattern
+	}
+	return ""
+}
+
+func (x *CacheEntriesFilter) GetMinAge() int64 {
+	if x != nil {
+		return x.MinAge
+	}
+	return 0
+}
+
+func (x *CacheEntriesFilter) GetKey() *SHA256Message {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type CacheEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileName     string         `protobuf:"bytes,1,opt,name=FileName,proto3" json:"FileName,omitempty"`
+	Key          *SHA256Message `protobuf:"bytes,2,opt,name=Key,proto3" json:"Key,omitempty"`
+	FileSize     int64          `protobuf:"varint,3,opt,name=FileSize,proto3" json:"FileSize,omitempty"`
+	OriginalSize int64          `protobuf:"varint,4,opt,name=OriginalSize,proto3" json:"OriginalSize,omitempty"`
+	// Age in nanoseconds since the last use
+	Age int64 `protobuf:"varint,5,opt,name=Age,proto3" json:"Age,omitempty"`
+}
+
+func (x *CacheEntry) Reset() {
+	*x = CacheEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheEntry) ProtoMessage() {}
+
+func (x *CacheEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheEntry.ProtoReflect.Descriptor instead.
+func (*CacheEntry) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CacheEntry) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *CacheEntry) GetKey() *SHA256Message {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *CacheEntry) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *CacheEntry) GetOriginalSize() int64 {
+	if x != nil {
+		return x.OriginalSize
+	}
+	return 0
+}
+
+func (x *CacheEntry) GetAge() int64 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+type ListCacheEntriesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filter *CacheEntriesFilter `protobuf:"bytes,1,opt,name=Filter,proto3" json:"Filter,omitempty"`
+	// Limit of returned entries, the most recently used are returned first
+	Limit int64 `protobuf:"varint,2,opt,name=Limit,proto3" json:"Limit,omitempty"`
+}
+
+func (x *ListCacheEntriesRequest) Reset() {
+	*x = ListCacheEntriesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCacheEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCacheEntriesRequest) ProtoMessage() {}
+
+func (x *ListCacheEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCacheEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListCacheEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListCacheEntriesRequest) GetFilter() *CacheEntriesFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListCacheEntriesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListCacheEntriesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries        []*CacheEntry `protobuf:"bytes,1,rep,name=Entries,proto3" json:"Entries,omitempty"`
+	MatchedEntries int64         `protobuf:"varint,2,opt,name=MatchedEntries,proto3" json:"MatchedEntries,omitempty"`
+}
+
+func (x *ListCacheEntriesReply) Reset() {
+	*x = ListCacheEntriesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCacheEntriesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCacheEntriesReply) ProtoMessage() {}
+
+func (x *ListCacheEntriesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCacheEntriesReply.ProtoReflect.Descriptor instead.
+func (*ListCacheEntriesReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListCacheEntriesReply) GetEntries() []*CacheEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListCacheEntriesReply) GetMatchedEntries() int64 {
+	if x != nil {
+		return x.MatchedEntries
+	}
+	return 0
+}
+
+type EvictCacheEntriesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filter *CacheEntriesFilter `protobuf:"bytes,1,opt,name=Filter,proto3" json:"Filter,omitempty"`
+	// All must be set for evicting the whole cache with an empty filter
+	All bool `protobuf:"varint,2,opt,name=All,proto3" json:"All,omitempty"`
+}
+
+func (x *EvictCacheEntriesRequest) Reset() {
+	*x = EvictCacheEntriesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvictCacheEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvictCacheEntriesRequest) ProtoMessage() {}
+
+func (x *EvictCacheEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvictCacheEntriesRequest.ProtoReflect.Descriptor instead.
+func (*EvictCacheEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *EvictCacheEntriesRequest) GetFilter() *CacheEntriesFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *EvictCacheEntriesRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type EvictCacheEntriesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EvictedFiles int64 `protobuf:"varint,1,opt,name=EvictedFiles,proto3" json:"EvictedFiles,omitempty"`
+	EvictedBytes int64 `protobuf:"varint,2,opt,name=EvictedBytes,proto3" json:"EvictedBytes,omitempty"`
+}
+
+func (x *EvictCacheEntriesReply) Reset() {
+	*x = EvictCacheEntriesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvictCacheEntriesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvictCacheEntriesReply) ProtoMessage() {}
+
+func (x *EvictCacheEntriesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvictCacheEntriesReply.ProtoReflect.Descriptor instead.
+func (*EvictCacheEntriesReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *EvictCacheEntriesReply) GetEvictedFiles() int64 {
+	if x != nil {
+		return x.EvictedFiles
+	}
+	return 0
+}
+
+func (x *EvictCacheEntriesReply) GetEvictedBytes() int64 {
+	if x != nil {
+		return x.EvictedBytes
+	}
+	return 0
+}
+
+type ResizeCacheRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cache     CacheType `protobuf:"varint,1,opt,name=Cache,proto3,enum=popcorn.CacheType" json:"Cache,omitempty"`
+	SizeLimit int64     `protobuf:"varint,2,opt,name=SizeLimit,proto3" json:"SizeLimit,omitempty"`
+}
+
+func (x *ResizeCacheRequest) Reset() {
+	*x = ResizeCacheRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResizeCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeCacheRequest) ProtoMessage() {}
+
+func (x *ResizeCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeCacheRequest.ProtoReflect.Descriptor instead.
+func (*ResizeCacheRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ResizeCacheRequest) GetCache() CacheType {
+	if x != nil {
+		return x.Cache
+	}
+	return CacheType_CACHE_OBJ
+}
+
+func (x *ResizeCacheRequest) GetSizeLimit() int64 {
+	if x != nil {
+		return x.SizeLimit
+	}
+	return 0
+}
+
+type ResizeCacheReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PreviousSizeLimit int64 `protobuf:"varint,1,opt,name=PreviousSizeLimit,proto3" json:"PreviousSizeLimit,omitempty"`
+	PurgedFiles       int64 `protobuf:"varint,2,opt,name=PurgedFiles,proto3" json:"PurgedFiles,omitempty"`
+}
+
+func (x *ResizeCacheReply) Reset() {
+	*x = ResizeCacheReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResizeCacheReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeCacheReply) ProtoMessage() {}
+
+func (x *ResizeCacheReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeCacheReply.ProtoReflect.Descriptor instead.
+func (*ResizeCacheReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ResizeCacheReply) GetPreviousSizeLimit() int64 {
+	if x != nil {
+		return x.PreviousSizeLimit
+	}
+	return 0
+}
+
+func (x *ResizeCacheReply) GetPurgedFiles() int64 {
+	if x != nil {
+		return x.PurgedFiles
+	}
+	return 0
+}
+
+type GetCachedObjectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      *SHA256Message `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`
+	FileName string         `protobuf:"bytes,2,opt,name=FileName,proto3" json:"FileName,omitempty"`
+}
+
+func (x *GetCachedObjectRequest) Reset() {
+	*x = GetCachedObjectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCachedObjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCachedObjectRequest) ProtoMessage() {}
+
+func (x *GetCachedObjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCachedObjectRequest.ProtoReflect.Descriptor instead.
+func (*GetCachedObjectRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetCachedObjectRequest) GetKey() *SHA256Message {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *GetCachedObjectRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+type GetCachedObjectReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ObjChunk []byte `protobuf:"bytes,1,opt,name=ObjChunk,proto3" json:"ObjChunk,omitempty"`
+}
+
+func (x *GetCachedObjectReply) Reset() {
+	*x = GetCachedObjectReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCachedObjectReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCachedObjectReply) ProtoMessage() {}
+
+func (x *GetCachedObjectReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCachedObjectReply.ProtoReflect.Descriptor instead.
+func (*GetCachedObjectReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetCachedObjectReply) GetObjChunk() []byte {
+	if x != nil {
+		return x.ObjChunk
+	}
+	return nil
+}
+
+type PutCachedObjectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Chunk:
+	//	*PutCachedObjectRequest_Header
+	//	*PutCachedObjectRequest_ObjChunk
+	Chunk isPutCachedObjectRequest_Chunk `protobuf_oneof:"Chunk"`
+}
+
+func (x *PutCachedObjectRequest) Reset() {
+	*x = PutCachedObjectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutCachedObjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCachedObjectRequest) ProtoMessage() {}
+
+func (x *PutCachedObjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCachedObjectRequest.ProtoReflect.Descriptor instead.
+func (*PutCachedObjectRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{29}
+}
+
+func (m *PutCachedObjectRequest) GetChunk() isPutCachedObjectRequest_Chunk {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+func (x *PutCachedObjectRequest) GetHeader() *PutCachedObjectRequest_StreamHeader {
+	if x, ok := x.GetChunk().(*PutCachedObjectRequest_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *PutCachedObjectRequest) GetObjChunk() []byte {
+	if x, ok := x.GetChunk().(*PutCachedObjectRequest_ObjChunk); ok {
+		return x.ObjChunk
+	}
+	return nil
+}
+
+type isPutCachedObjectRequest_Chunk interface {
+	isPutCachedObjectRequest_Chunk()
+}
+
+type PutCachedObjectRequest_Header struct {
+	Header *PutCachedObjectRequest_StreamHeader `protobuf:"bytes,1,opt,name=Header,proto3,oneof"`
+}
+
+type PutCachedObjectRequest_ObjChunk struct {
+	ObjChunk []byte `protobuf:"bytes,2,opt,name=ObjChunk,proto3,oneof"`
+}
+
+func (*PutCachedObjectRequest_Header) isPutCachedObjectRequest_Chunk() {}
+
+func (*PutCachedObjectRequest_ObjChunk) isPutCachedObjectRequest_Chunk() {}
+
+type PutCachedObjectReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Saved bool `protobuf:"varint,1,opt,name=Saved,proto3" json:"Saved,omitempty"`
+}
+
+func (x *PutCachedObjectReply) Reset() {
+	*x = PutCachedObjectReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutCachedObjectReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCachedObjectReply) ProtoMessage() {}
+
+func (x *PutCachedObjectReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCachedObjectReply.ProtoReflect.Descriptor instead.
+func (*PutCachedObjectReply) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PutCachedObjectReply) GetSaved() bool {
+	if x != nil {
+		return x.Saved
+	}
+	return false
+}
+
+type TransferFileRequest_StreamHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SessionID  uint64         `protobuf:"varint,1,opt,name=SessionID,proto3" json:"SessionID,omitempty"`
+	FileIndex  uint32         `protobuf:"varint,2,opt,name=FileIndex,proto3" json:"FileIndex,omitempty"`
+	FileSHA256 *SHA256Message `protobuf:"bytes,3,opt,name=FileSHA256,proto3" json:"FileSHA256,omitempty"`
+}
+
+func (x *TransferFileRequest_StreamHeader) Reset() {
+	*x = TransferFileRequest_StreamHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferFileRequest_StreamHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferFileRequest_StreamHeader) ProtoMessage() {}
+
+func (x *TransferFileRequest_StreamHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferFileRequest_StreamHeader.ProtoReflect.Descriptor instead.
+func (*TransferFileRequest_StreamHeader) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{5, 0}
+}
+
+func (x *TransferFileRequest_StreamHeader) GetSessionID() uint64 {
+	if x != nil {
+		return x.SessionID
+	}
+	return 0
+}
+
+func (x *TransferFileRequest_StreamHeader) GetFileIndex() uint32 {
+	if x != nil {
+		return x.FileIndex
+	}
+	return 0
+}
+
+func (x *TransferFileRequest_StreamHeader) GetFileSHA256() *SHA256Message {
+	if x != nil {
+		return x.FileSHA256
+	}
+	return nil
+}
+
+type CompileSourceReply_StreamEpilogue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CompilerRetCode   int32             `protobuf:"varint,1,opt,name=CompilerRetCode,proto3" json:"CompilerRetCode,omitempty"`
+	CompilerStdout    []byte            `protobuf:"bytes,2,opt,name=CompilerStdout,proto3" json:"CompilerStdout,omitempty"`
+	CompilerStderr    []byte            `protobuf:"bytes,3,opt,name=CompilerStderr,proto3" json:"CompilerStderr,omitempty"`
+	ObjectCacheResult ObjectCacheResult `protobuf:"varint,5,opt,name=ObjectCacheResult,proto3,enum=popcorn.ObjectCacheResult" json:"ObjectCacheResult,omitempty"`
+	// Times in nanoseconds
+	QueueWaitTime int64 `protobuf:"varint,6,opt,name=QueueWaitTime,proto3" json:"QueueWaitTime,omitempty"`
+	CompileTime   int64 `protobuf:"varint,7,opt,name=CompileTime,proto3" json:"CompileTime,omitempty"`
+	// Required files by the source: uploaded by the client, found in the source cache and system headers
+	UploadedFiles     int32  `protobuf:"varint,8,opt,name=UploadedFiles,proto3" json:"UploadedFiles,omitempty"`
+	FilesFromSrcCache int32  `protobuf:"varint,9,opt,name=FilesFromSrcCache,proto3" json:"FilesFromSrcCache,omitempty"`
+	SystemHeaders     int32  `protobuf:"varint,10,opt,name=SystemHeaders,proto3" json:"SystemHeaders,omitempty"`
+	ServerName        string `protobuf:"bytes,11,opt,name=ServerName,proto3" json:"ServerName,omitempty"`
+	ServerVersion     string `protobuf:"bytes,12,opt,name=ServerVersion,proto3" json:"ServerVersion,omitempty"`
+}
+
+func (x *CompileSourceReply_StreamEpilogue) Reset() {
+	*x = CompileSourceReply_StreamEpilogue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompileSourceReply_StreamEpilogue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompileSourceReply_StreamEpilogue) ProtoMessage() {}
+
+func (x *CompileSourceReply_StreamEpilogue) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompileSourceReply_StreamEpilogue.ProtoReflect.Descriptor instead.
+func (*CompileSourceReply_StreamEpilogue) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetCompilerRetCode() int32 {
+	if x != nil {
+		return x.CompilerRetCode
+	}
+	return 0
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetCompilerStdout() []byte {
+	if x != nil {
+		return x.CompilerStdout
+	}
+	return nil
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetCompilerStderr() []byte {
+	if x != nil {
+		return x.CompilerStderr
+	}
+	return nil
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetObjectCacheResult() ObjectCacheResult {
+	if x != nil {
+		return x.ObjectCacheResult
+	}
+	return ObjectCacheResult_OBJ_CACHE_RESULT_SKIPPED
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetQueueWaitTime() int64 {
+	if x != nil {
+		return x.QueueWaitTime
+	}
+	return 0
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetCompileTime() int64 {
+	if x != nil {
+		return x.CompileTime
+	}
+	return 0
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetUploadedFiles() int32 {
+	if x != nil {
+		return x.UploadedFiles
+	}
+	return 0
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetFilesFromSrcCache() int32 {
+	if x != nil {
+		return x.FilesFromSrcCache
+	}
+	return 0
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetSystemHeaders() int32 {
+	if x != nil {
+		return x.SystemHeaders
+	}
+	return 0
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *CompileSourceReply_StreamEpilogue) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+type PutCachedObjectRequest_StreamHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      *SHA256Message `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`
+	FileName string         `protobuf:"bytes,2,opt,name=FileName,proto3" json:"FileName,omitempty"`
+}
+
+func (x *PutCachedObjectRequest_StreamHeader) Reset() {
+	*x = PutCachedObjectRequest_StreamHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_v1_compilation_server_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutCachedObjectRequest_StreamHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCachedObjectRequest_StreamHeader) ProtoMessage() {}
+
+func (x *PutCachedObjectRequest_StreamHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_v1_compilation_server_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCachedObjectRequest_StreamHeader.ProtoReflect.Descriptor instead.
+func (*PutCachedObjectRequest_StreamHeader) Descriptor() ([]byte, []int) {
+	return file_api_proto_v1_compilation_server_proto_rawDescGZIP(), []int{29, 0}
+}
+
+func (x *PutCachedObjectRequest_StreamHeader) GetKey() *SHA256Message {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *PutCachedObjectRequest_StreamHeader) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+var File_api_proto_v1_compilation_server_proto protoreflect.FileDescriptor
+
+var file_api_proto_v1_compilation_server_proto_rawDesc = []byte{
+	0x0a, 0x25, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65,
 	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e,
 	0x22, 0x65, 0x0a, 0x0d, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
 	0x65, 0x12, 0x11, 0x0a, 0x04, 0x42, 0x30, 0x5f, 0x37, 0x18, 0x01, 0x20, 0x01, 0x28, 0x06, 0x52,
@@ -1022,7 +2509,7 @@ var file_api_proto_v1_compilation_server_proto_rawDesc = []byte{
 	0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x4d, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
 	0x28, 0x03, 0x52, 0x05, 0x4d, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x69, 0x6c,
 	0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x46, 0x69, 0x6c,
-	0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0xc9, 0x02, 0x0a, 0x1e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0xc7, 0x03, 0x0a, 0x1e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43,
 	0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
 	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x08, 0x43, 0x6c, 0x69, 0x65,
 	0x6e, 0x74, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f, 0x70,
@@ -1043,120 +2530,358 @@ var file_api_proto_v1_compilation_server_proto_rawDesc = []byte{
 	0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x55, 0x73, 0x65,
 	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
 	0x08, 0x52, 0x0e, 0x55, 0x73, 0x65, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68,
-	0x65, 0x22, 0x5d, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c,
-	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12,
-	0x2f, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x17, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72,
-	0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x22, 0x79, 0x0a, 0x1c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x1c, 0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x3b,
-	0x0a, 0x0d, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e,
-	0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x0d, 0x52, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x90, 0x02, 0x0a, 0x13,
-	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x43, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x54, 0x72,
+	0x65, 0x12, 0x38, 0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f,
+	0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74,
+	0x79, 0x52, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x42, 0x0a, 0x0f, 0x4f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x4f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0f,
+	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x22,
+	0x5d, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2f, 0x0a,
+	0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e,
+	0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x79,
+	0x0a, 0x1c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1c,
+	0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x3b, 0x0a, 0x0d,
+	0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65,
+	0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x0d, 0x52, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x90, 0x02, 0x0a, 0x13, 0x54, 0x72,
 	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00,
-	0x52, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x0d, 0x46, 0x69, 0x6c, 0x65,
-	0x42, 0x6f, 0x64, 0x79, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48,
-	0x00, 0x52, 0x0d, 0x46, 0x69, 0x6c, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x43, 0x68, 0x75, 0x6e, 0x6b,
-	0x1a, 0x82, 0x01, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12,
-	0x1c, 0x0a, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x36, 0x0a,
-	0x0a, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x48, 0x41, 0x32,
-	0x35, 0x36, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x53,
-	0x48, 0x41, 0x32, 0x35, 0x36, 0x42, 0x07, 0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x44,
-	0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x22, 0x6c, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09,
-	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x36, 0x0a, 0x16, 0x43, 0x6c,
-	0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x74, 0x65, 0x72, 0x42,
-	0x75, 0x69, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x16, 0x43, 0x6c, 0x6f, 0x73,
+	0x74, 0x12, 0x43, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x29, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x0d, 0x46, 0x69, 0x6c, 0x65, 0x42, 0x6f,
+	0x64, 0x79, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52,
+	0x0d, 0x46, 0x69, 0x6c, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x1a, 0x82,
+	0x01, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
+	0x1c, 0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x1c, 0x0a,
+	0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x36, 0x0a, 0x0a, 0x46,
+	0x69, 0x6c, 0x65, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x48, 0x41,
+	0x32, 0x35, 0x36, 0x42, 0x07, 0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x44, 0x0a, 0x11,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x17, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x22, 0x6c, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x36, 0x0a, 0x16, 0x43, 0x6c, 0x6f, 0x73,
 	0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x74, 0x65, 0x72, 0x42, 0x75, 0x69,
-	0x6c, 0x64, 0x22, 0xa2, 0x02, 0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2c, 0x0a, 0x10, 0x43, 0x6f, 0x6d,
-	0x70, 0x69, 0x6c, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x64, 0x4f,
-	0x62, 0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x48, 0x0a, 0x08, 0x45, 0x70, 0x69, 0x6c, 0x6f,
-	0x67, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x70, 0x6f, 0x70, 0x63,
-	0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x70, 0x69,
-	0x6c, 0x6f, 0x67, 0x75, 0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x70, 0x69, 0x6c, 0x6f, 0x67, 0x75,
-	0x65, 0x1a, 0x8a, 0x01, 0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x70, 0x69, 0x6c,
-	0x6f, 0x67, 0x75, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72,
-	0x52, 0x65, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x43,
-	0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x26,
-	0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x53, 0x74, 0x64, 0x6f, 0x75, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72,
-	0x53, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
-	0x65, 0x72, 0x53, 0x74, 0x64, 0x65, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e,
-	0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x53, 0x74, 0x64, 0x65, 0x72, 0x72, 0x42, 0x07,
-	0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x33, 0x0a, 0x13, 0x43, 0x6c, 0x6f, 0x73, 0x65,
-	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c,
-	0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x22, 0x13, 0x0a, 0x11,
-	0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x22, 0x35, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x69,
-	0x6c, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b,
-	0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x22, 0xa1, 0x01, 0x0a, 0x0b, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x24, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e,
-	0x0a, 0x0a, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x0a, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x72, 0x67, 0x73, 0x12, 0x22,
-	0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x55, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x55, 0x70, 0x74, 0x69,
-	0x6d, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x56, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x43, 0x6f, 0x6d,
-	0x70, 0x69, 0x6c, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x2a, 0x47, 0x0a, 0x0e,
-	0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x08,
-	0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x48, 0x41, 0x32,
-	0x35, 0x36, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x49, 0x52, 0x45, 0x44, 0x10, 0x01, 0x12, 0x16, 0x0a,
-	0x12, 0x46, 0x55, 0x4c, 0x4c, 0x5f, 0x43, 0x4f, 0x50, 0x59, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x49,
-	0x52, 0x45, 0x44, 0x10, 0x02, 0x32, 0xa8, 0x03, 0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6b, 0x0a, 0x17,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72,
-	0x6e, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x25, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74,
-	0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69,
-	0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0c, 0x54, 0x72, 0x61,
-	0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1c, 0x2e, 0x70, 0x6f, 0x70, 0x63,
-	0x6f, 0x72, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72,
-	0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x4f, 0x0a, 0x0d, 0x43, 0x6f, 0x6d,
-	0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x2e, 0x70, 0x6f, 0x70,
-	0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x6f, 0x70, 0x63,
-	0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x4a, 0x0a, 0x0c, 0x43, 0x6c,
-	0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x2e, 0x70, 0x6f, 0x70,
-	0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
-	0x72, 0x6e, 0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x38, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
-	0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
-	0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x41,
-	0x6c, 0x65, 0x78, 0x4b, 0x30, 0x2f, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2f, 0x69, 0x6e,
-	0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x6f, 0x70, 0x63, 0x6f,
-	0x72, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x16, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x74, 0x65, 0x72, 0x42, 0x75, 0x69, 0x6c, 0x64,
+	0x22, 0xfa, 0x04, 0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2c, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x69,
+	0x6c, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x64, 0x4f, 0x62, 0x6a,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x48, 0x0a, 0x08, 0x45, 0x70, 0x69, 0x6c, 0x6f, 0x67, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72,
+	0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x70, 0x69, 0x6c, 0x6f,
+	0x67, 0x75, 0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x70, 0x69, 0x6c, 0x6f, 0x67, 0x75, 0x65, 0x1a,
+	0xe2, 0x03, 0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x70, 0x69, 0x6c, 0x6f, 0x67,
+	0x75, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x52, 0x65,
+	0x74, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x43, 0x6f, 0x6d,
+	0x70, 0x69, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x26, 0x0a, 0x0e,
+	0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x53, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x53, 0x74,
+	0x64, 0x6f, 0x75, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72,
+	0x53, 0x74, 0x64, 0x65, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x43, 0x6f,
+	0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x53, 0x74, 0x64, 0x65, 0x72, 0x72, 0x12, 0x48, 0x0a, 0x11,
+	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72,
+	0x6e, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x11, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x51, 0x75, 0x65, 0x75, 0x65, 0x57,
+	0x61, 0x69, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x57, 0x61, 0x69, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b,
+	0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x24,
+	0x0a, 0x0d, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x46,
+	0x69, 0x6c, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x11, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x46, 0x72, 0x6f,
+	0x6d, 0x53, 0x72, 0x63, 0x43, 0x61, 0x63, 0x68, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x11, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x46, 0x72, 0x6f, 0x6d, 0x53, 0x72, 0x63, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x53, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x53, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4a, 0x04,
+	0x08, 0x04, 0x10, 0x05, 0x42, 0x07, 0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x33, 0x0a,
+	0x13, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x49, 0x44, 0x22, 0x13, 0x0a, 0x11, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x35, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x22, 0xe5,
+	0x04, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x24,
+	0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x72,
+	0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x41, 0x72, 0x67, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x55, 0x70,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x55, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70,
+	0x69, 0x6c, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x13, 0x52, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6d,
+	0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x13, 0x52, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x43, 0x6f,
+	0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x12, 0x51, 0x75, 0x65, 0x75, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x38, 0x0a, 0x17, 0x4d, 0x61, 0x78, 0x50, 0x61, 0x72, 0x61, 0x6c,
+	0x6c, 0x65, 0x6c, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x4d, 0x61, 0x78, 0x50, 0x61, 0x72, 0x61, 0x6c, 0x6c,
+	0x65, 0x6c, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x43, 0x50,
+	0x55, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x43, 0x50,
+	0x55, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x4c, 0x6f, 0x61, 0x64, 0x41, 0x76,
+	0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0b, 0x4c, 0x6f, 0x61,
+	0x64, 0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x41, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x2b, 0x0a, 0x06, 0x43, 0x61, 0x63, 0x68, 0x65, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x06, 0x43, 0x61, 0x63, 0x68, 0x65, 0x73, 0x12, 0x33, 0x0a,
+	0x09, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x72, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69,
+	0x6c, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65,
+	0x72, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69,
+	0x65, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x22, 0x44, 0x0a, 0x0c, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
+	0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
+	0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x0e, 0x0a, 0x0c,
+	0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x34, 0x0a, 0x0a,
+	0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x26, 0x0a, 0x0e, 0x41, 0x63,
+	0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0xa2, 0x02, 0x0a, 0x0a, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x28, 0x0a, 0x05, 0x43, 0x61, 0x63, 0x68, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x12, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x05, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x52,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x52, 0x65, 0x6d,
+	0x6f, 0x74, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x42, 0x79, 0x74, 0x65, 0x73, 0x4f, 0x6e, 0x44, 0x69,
+	0x73, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x42, 0x79, 0x74, 0x65, 0x73, 0x4f,
+	0x6e, 0x44, 0x69, 0x73, 0x6b, 0x12, 0x24, 0x0a, 0x0d, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61,
+	0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x4f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x50,
+	0x75, 0x72, 0x67, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x50, 0x75, 0x72, 0x67, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x53, 0x69, 0x7a, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x53, 0x69, 0x7a, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x48,
+	0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x48, 0x69, 0x74, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x4d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x4d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x22, 0x16, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0x41, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2b, 0x0a, 0x06, 0x43, 0x61, 0x63, 0x68, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e,
+	0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x06, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x73, 0x22, 0xaa, 0x01, 0x0a, 0x12, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x05, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
+	0x72, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x05, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x50,
+	0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x46, 0x69,
+	0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x4d, 0x69, 0x6e, 0x41, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x4d,
+	0x69, 0x6e, 0x41, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x03, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x48, 0x41,
+	0x32, 0x35, 0x36, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x03, 0x4b, 0x65, 0x79, 0x22,
+	0xa4, 0x01, 0x0a, 0x0a, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1a,
+	0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x03, 0x4b, 0x65,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72,
+	0x6e, 0x2e, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x03, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x22, 0x0a, 0x0c, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x41, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x03, 0x41, 0x67, 0x65, 0x22, 0x64, 0x0a, 0x17, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x33, 0x0a, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x6e, 0x0a, 0x15,
+	0x4c, 0x69, 0x73, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x07, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e,
+	0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x45,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x61, 0x0a, 0x18,
+	0x45, 0x76, 0x69, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x06, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
+	0x72, 0x6e, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x46,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x10, 0x0a,
+	0x03, 0x41, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x41, 0x6c, 0x6c, 0x22,
+	0x60, 0x0a, 0x16, 0x45, 0x76, 0x69, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x45, 0x76, 0x69,
+	0x63, 0x74, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0c, 0x45, 0x76, 0x69, 0x63, 0x74, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x22, 0x0a,
+	0x0c, 0x45, 0x76, 0x69, 0x63, 0x74, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x45, 0x76, 0x69, 0x63, 0x74, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x22, 0x5c, 0x0a, 0x12, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x05, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e,
+	0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x05, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x7a, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x53, 0x69, 0x7a, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22,
+	0x62, 0x0a, 0x10, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53,
+	0x69, 0x7a, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11,
+	0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x69, 0x7a, 0x65, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x20, 0x0a, 0x0b, 0x50, 0x75, 0x72, 0x67, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x50, 0x75, 0x72, 0x67, 0x65, 0x64, 0x46, 0x69,
+	0x6c, 0x65, 0x73, 0x22, 0x5e, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64,
+	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a,
+	0x03, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f, 0x70,
+	0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x52, 0x03, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x22, 0x32, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64,
+	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x4f,
+	0x62, 0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x4f,
+	0x62, 0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0xdd, 0x01, 0x0a, 0x16, 0x50, 0x75, 0x74, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x46, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x50, 0x75, 0x74,
+	0x43, 0x61, 0x63, 0x68, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x48, 0x00, 0x52, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x08, 0x4f, 0x62,
+	0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x08,
+	0x4f, 0x62, 0x6a, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x1a, 0x54, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x03, 0x4b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e,
+	0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x03, 0x4b,
+	0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x07,
+	0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x2c, 0x0a, 0x14, 0x50, 0x75, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x53, 0x61, 0x76, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05,
+	0x53, 0x61, 0x76, 0x65, 0x64, 0x2a, 0x58, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x13, 0x0a, 0x0f,
+	0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x4e, 0x4f, 0x52, 0x4d, 0x41, 0x4c, 0x10,
+	0x00, 0x12, 0x18, 0x0a, 0x14, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x49, 0x4e,
+	0x54, 0x45, 0x52, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x50,
+	0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x42, 0x41, 0x54, 0x43, 0x48, 0x10, 0x02, 0x2a,
+	0x9f, 0x01, 0x0a, 0x0f, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x42, 0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45,
+	0x5f, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x4f, 0x42,
+	0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x4f, 0x46, 0x46, 0x10, 0x01, 0x12, 0x18, 0x0a,
+	0x14, 0x4f, 0x42, 0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x5f,
+	0x57, 0x52, 0x49, 0x54, 0x45, 0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x4f, 0x42, 0x4a, 0x5f, 0x43,
+	0x41, 0x43, 0x48, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10, 0x03,
+	0x12, 0x18, 0x0a, 0x14, 0x4f, 0x42, 0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x57, 0x52,
+	0x49, 0x54, 0x45, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x42,
+	0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x52, 0x45, 0x43, 0x41, 0x43, 0x48, 0x45, 0x10,
+	0x05, 0x2a, 0x47, 0x0a, 0x0e, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x13, 0x0a,
+	0x0f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x49, 0x52, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x55, 0x4c, 0x4c, 0x5f, 0x43, 0x4f, 0x50, 0x59, 0x5f,
+	0x52, 0x45, 0x51, 0x55, 0x49, 0x52, 0x45, 0x44, 0x10, 0x02, 0x2a, 0x85, 0x01, 0x0a, 0x11, 0x4f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x1c, 0x0a, 0x18, 0x4f, 0x42, 0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x52, 0x45,
+	0x53, 0x55, 0x4c, 0x54, 0x5f, 0x53, 0x4b, 0x49, 0x50, 0x50, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19,
+	0x0a, 0x15, 0x4f, 0x42, 0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x52, 0x45, 0x53, 0x55,
+	0x4c, 0x54, 0x5f, 0x4d, 0x49, 0x53, 0x53, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x4f, 0x42, 0x4a,
+	0x5f, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x48, 0x49,
+	0x54, 0x10, 0x02, 0x12, 0x1d, 0x0a, 0x19, 0x4f, 0x42, 0x4a, 0x5f, 0x43, 0x41, 0x43, 0x48, 0x45,
+	0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x50, 0x45, 0x45, 0x52, 0x5f, 0x48, 0x49, 0x54,
+	0x10, 0x03, 0x2a, 0x29, 0x0a, 0x09, 0x43, 0x61, 0x63, 0x68, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x0d, 0x0a, 0x09, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x4f, 0x42, 0x4a, 0x10, 0x00, 0x12, 0x0d,
+	0x0a, 0x09, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x53, 0x52, 0x43, 0x10, 0x01, 0x32, 0xd8, 0x07,
+	0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x6b, 0x0a, 0x17, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d,
+	0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x27, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43,
+	0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
+	0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x4e, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c,
+	0x65, 0x12, 0x1c, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1a, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x30,
+	0x01, 0x12, 0x4f, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x12, 0x1d, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f, 0x6d,
+	0x70, 0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x69, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x30, 0x01, 0x12, 0x4a, 0x0a, 0x0c, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6c, 0x6f,
+	0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1a, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x38,
+	0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
+	0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x14, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x05, 0x44, 0x72, 0x61, 0x69,
+	0x6e, 0x12, 0x15, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x44, 0x72, 0x61, 0x69,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f,
+	0x72, 0x6e, 0x2e, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x4d, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x12, 0x1d, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x56,
+	0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x12, 0x20, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x59, 0x0a, 0x11, 0x45, 0x76, 0x69, 0x63, 0x74, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x21, 0x2e, 0x70, 0x6f,
+	0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x45, 0x76, 0x69, 0x63, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x45, 0x76, 0x69, 0x63, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x47, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x12, 0x1b, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x69, 0x7a,
+	0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
+	0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0f, 0x47, 0x65,
+	0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x1f, 0x2e,
+	0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d,
+	0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30,
+	0x01, 0x12, 0x55, 0x0a, 0x0f, 0x50, 0x75, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64, 0x4f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x12, 0x1f, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e, 0x50,
+	0x75, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2e,
+	0x50, 0x75, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x41, 0x6c, 0x65, 0x78, 0x4b, 0x30, 0x2f, 0x70, 0x6f,
+	0x70, 0x63, 0x6f, 0x72, 0x6e, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x61,
+	0x70, 0x69, 0x2f, 0x70, 0x6f, 0x70, 0x63, 0x6f, 0x72, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -1171,50 +2896,104 @@ func file_api_proto_v1_compilation_server_proto_rawDescGZIP() []byte {
 	return file_api_proto_v1_compilation_server_proto_rawDescData
 }
 
-var file_api_proto_v1_compilation_server_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_proto_v1_compilation_server_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_api_proto_v1_compilation_server_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_api_proto_v1_compilation_server_proto_msgTypes = make([]protoimpl.MessageInfo, 34)
 var file_api_proto_v1_compilation_server_proto_goTypes = []interface{}{
-	(RequiredStatus)(0),                       // 0: popcorn.RequiredStatus
-	(*SHA256Message)(nil),                     // 1: popcorn.SHA256Message
-	(*FileMetadata)(nil),                      // 2: popcorn.FileMetadata
-	(*StartCompilationSessionRequest)(nil),    // 3: popcorn.StartCompilationSessionRequest
-	(*RequiredFile)(nil),                      // 4: popcorn.RequiredFile
-	(*StartCompilationSessionReply)(nil),      // 5: popcorn.StartCompilationSessionReply
-	(*TransferFileRequest)(nil),               // 6: popcorn.TransferFileRequest
-	(*TransferFileReply)(nil),                 // 7: popcorn.TransferFileReply
-	(*CompileSourceRequest)(nil),              // 8: popcorn.CompileSourceRequest
-	(*CompileSourceReply)(nil),                // 9: popcorn.CompileSourceReply
-	(*CloseSessionRequest)(nil),               // 10: popcorn.CloseSessionRequest
-	(*CloseSessionReply)(nil),                 // 11: popcorn.CloseSessionReply
-	(*StatusRequest)(nil),                     // 12: popcorn.StatusRequest
-	(*StatusReply)(nil),                       // 13: popcorn.StatusReply
-	(*TransferFileRequest_StreamHeader)(nil),  // 14: popcorn.TransferFileRequest.StreamHeader
-	(*CompileSourceReply_StreamEpilogue)(nil), // 15: popcorn.CompileSourceReply.StreamEpilogue
+	(CompilationPriority)(0),                    // 0: popcorn.CompilationPriority
+	(ObjectCacheMode)(0),                        // 1: popcorn.ObjectCacheMode
+	(RequiredStatus)(0),                         // 2: popcorn.RequiredStatus
+	(ObjectCacheResult)(0),                      // 3: popcorn.ObjectCacheResult
+	(CacheType)(0),                              // 4: popcorn.CacheType
+	(*SHA256Message)(nil),                       // 5: popcorn.SHA256Message
+	(*FileMetadata)(nil),                        // 6: popcorn.FileMetadata
+	(*StartCompilationSessionRequest)(nil),      // 7: popcorn.StartCompilationSessionRequest
+	(*RequiredFile)(nil),                        // 8: popcorn.RequiredFile
+	(*StartCompilationSessionReply)(nil),        // 9: popcorn.StartCompilationSessionReply
+	(*TransferFileRequest)(nil),                 // 10: popcorn.TransferFileRequest
+	(*TransferFileReply)(nil),                   // 11: popcorn.TransferFileReply
+	(*CompileSourceRequest)(nil),                // 12: popcorn.CompileSourceRequest
+	(*CompileSourceReply)(nil),                  // 13: popcorn.CompileSourceReply
+	(*CloseSessionRequest)(nil),                 // 14: popcorn.CloseSessionRequest
+	(*CloseSessionReply)(nil),                   // 15: popcorn.CloseSessionReply
+	(*StatusRequest)(nil),                       // 16: popcorn.StatusRequest
+	(*StatusReply)(nil),                         // 17: popcorn.StatusReply
+	(*CompilerInfo)(nil),                        // 18: popcorn.CompilerInfo
+	(*DrainRequest)(nil),                        // 19: popcorn.DrainRequest
+	(*DrainReply)(nil),                          // 20: popcorn.DrainReply
+	(*CacheStats)(nil),                          // 21: popcorn.CacheStats
+	(*GetCacheStatsRequest)(nil),                // 22: popcorn.GetCacheStatsRequest
+	(*GetCacheStatsReply)(nil),                  // 23: popcorn.GetCacheStatsReply
+	(*CacheEntriesFilter)(nil),                  // 24: popcorn.CacheEntriesFilter
+	(*CacheEntry)(nil),                          // 25: popcorn.CacheEntry
+	(*ListCacheEntriesRequest)(nil),             // 26: popcorn.ListCacheEntriesRequest
+	(*ListCacheEntriesReply)(nil),               // 27: popcorn.ListCacheEntriesReply
+	(*EvictCacheEntriesRequest)(nil),            // 28: popcorn.EvictCacheEntriesRequest
+	(*EvictCacheEntriesReply)(nil),              // 29: popcorn.EvictCacheEntriesReply
+	(*ResizeCacheRequest)(nil),                  // 30: popcorn.ResizeCacheRequest
+	(*ResizeCacheReply)(nil),                    // 31: popcorn.ResizeCacheReply
+	(*GetCachedObjectRequest)(nil),              // 32: popcorn.GetCachedObjectRequest
+	(*GetCachedObjectReply)(nil),                // 33: popcorn.GetCachedObjectReply
+	(*PutCachedObjectRequest)(nil),              // 34: popcorn.PutCachedObjectRequest
+	(*PutCachedObjectReply)(nil),                // 35: popcorn.PutCachedObjectReply
+	(*TransferFileRequest_StreamHeader)(nil),    // 36: popcorn.TransferFileRequest.StreamHeader
+	(*CompileSourceReply_StreamEpilogue)(nil),   // 37: popcorn.CompileSourceReply.StreamEpilogue
+	(*PutCachedObjectRequest_StreamHeader)(nil), // 38: popcorn.PutCachedObjectRequest.StreamHeader
 }
 var file_api_proto_v1_compilation_server_proto_depIdxs = []int32{
-	1,  // 0: popcorn.StartCompilationSessionRequest.ClientID:type_name -> popcorn.SHA256Message
-	2,  // 1: popcorn.StartCompilationSessionRequest.RequiredFiles:type_name -> popcorn.FileMetadata
-	0,  // 2: popcorn.RequiredFile.Status:type_name -> popcorn.RequiredStatus
-	4,  // 3: popcorn.StartCompilationSessionReply.RequiredFiles:type_name -> popcorn.RequiredFile
-	14, // 4: popcorn.TransferFileRequest.Header:type_name -> popcorn.TransferFileRequest.StreamHeader
-	0,  // 5: popcorn.TransferFileReply.status:type_name -> popcorn.RequiredStatus
-	15, // 6: popcorn.CompileSourceReply.Epilogue:type_name -> popcorn.CompileSourceReply.StreamEpilogue
-	1,  // 7: popcorn.TransferFileRequest.StreamHeader.FileSHA256:type_name -> popcorn.SHA256Message
-	3,  // 8: popcorn.CompilationService.StartCompilationSession:input_type -> popcorn.StartCompilationSessionRequest
-	6,  // 9: popcorn.CompilationService.TransferFile:input_type -> popcorn.TransferFileRequest
-	8,  // 10: popcorn.CompilationService.CompileSource:input_type -> popcorn.CompileSourceRequest
-	10, // 11: popcorn.CompilationService.CloseSession:input_type -> popcorn.CloseSessionRequest
-	12, // 12: popcorn.CompilationService.Status:input_type -> popcorn.StatusRequest
-	5,  // 13: popcorn.CompilationService.StartCompilationSession:output_type -> popcorn.StartCompilationSessionReply
-	7,  // 14: popcorn.CompilationService.TransferFile:output_type -> popcorn.TransferFileReply
-	9,  // 15: popcorn.CompilationService.CompileSource:output_type -> popcorn.CompileSourceReply
-	11, // 16: popcorn.CompilationService.CloseSession:output_type -> popcorn.CloseSessionReply
-	13, // 17: popcorn.CompilationService.Status:output_type -> popcorn.StatusReply
-	13, // [13:18] is the sub-list for method output_type
-	8,  // [8:13] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	5,  // 0: popcorn.StartCompilationSessionRequest.ClientID:type_name -> popcorn.SHA256Message
+	6,  // 1: popcorn.StartCompilationSessionRequest.RequiredFiles:type_name -> popcorn.FileMetadata
+	0,  // 2: popcorn.StartCompilationSessionRequest.Priority:type_name -> popcorn.CompilationPriority
+	1,  // 3: popcorn.StartCompilationSessionRequest.ObjectCacheMode:type_name -> popcorn.ObjectCacheMode
+	2,  // 4: popcorn.RequiredFile.Status:type_name -> popcorn.RequiredStatus
+	8,  // 5: popcorn.StartCompilationSessionReply.RequiredFiles:type_name -> popcorn.RequiredFile
+	36, // 6: popcorn.TransferFileRequest.Header:type_name -> popcorn.TransferFileRequest.StreamHeader
+	2,  // 7: popcorn.TransferFileReply.status:type_name -> popcorn.RequiredStatus
+	37, // 8: popcorn.CompileSourceReply.Epilogue:type_name -> popcorn.CompileSourceReply.StreamEpilogue
+	21, // 9: popcorn.StatusReply.Caches:type_name -> popcorn.CacheStats
+	18, // 10: popcorn.StatusReply.Compilers:type_name -> popcorn.CompilerInfo
+	4,  // 11: popcorn.CacheStats.Cache:type_name -> popcorn.CacheType
+	21, // 12: popcorn.GetCacheStatsReply.Caches:type_name -> popcorn.CacheStats
+	4,  // 13: popcorn.CacheEntriesFilter.Cache:type_name -> popcorn.CacheType
+	5,  // 14: popcorn.CacheEntriesFilter.Key:type_name -> popcorn.SHA256Message
+	5,  // 15: popcorn.CacheEntry.Key:type_name -> popcorn.SHA256Message
+	24, // 16: popcorn.ListCacheEntriesRequest.Filter:type_name -> popcorn.CacheEntriesFilter
+	25, // 17: popcorn.ListCacheEntriesReply.Entries:type_name -> popcorn.CacheEntry
+	24, // 18: popcorn.EvictCacheEntriesRequest.Filter:type_name -> popcorn.CacheEntriesFilter
+	4,  // 19: popcorn.ResizeCacheRequest.Cache:type_name -> popcorn.CacheType
+	5,  // 20: popcorn.GetCachedObjectRequest.Key:type_name -> popcorn.SHA256Message
+	38, // 21: popcorn.PutCachedObjectRequest.Header:type_name -> popcorn.PutCachedObjectRequest.StreamHeader
+	5,  // 22: popcorn.TransferFileRequest.StreamHeader.FileSHA256:type_name -> popcorn.SHA256Message
+	3,  // 23: popcorn.CompileSourceReply.StreamEpilogue.ObjectCacheResult:type_name -> popcorn.ObjectCacheResult
+	5,  // 24: popcorn.PutCachedObjectRequest.StreamHeader.Key:type_name -> popcorn.SHA256Message
+	7,  // 25: popcorn.CompilationService.StartCompilationSession:input_type -> popcorn.StartCompilationSessionRequest
+	10, // 26: popcorn.CompilationService.TransferFile:input_type -> popcorn.TransferFileRequest
+	12, // 27: popcorn.CompilationService.CompileSource:input_type -> popcorn.CompileSourceRequest
+	14, // 28: popcorn.CompilationService.CloseSession:input_type -> popcorn.CloseSessionRequest
+	16, // 29: popcorn.CompilationService.Status:input_type -> popcorn.StatusRequest
+	19, // 30: popcorn.CompilationService.Drain:input_type -> popcorn.DrainRequest
+	22, // 31: popcorn.CompilationService.GetCacheStats:input_type -> popcorn.GetCacheStatsRequest
+	26, // 32: popcorn.CompilationService.ListCacheEntries:input_type -> popcorn.ListCacheEntriesRequest
+	28, // 33: popcorn.CompilationService.EvictCacheEntries:input_type -> popcorn.EvictCacheEntriesRequest
+	30, // 34: popcorn.CompilationService.ResizeCache:input_type -> popcorn.ResizeCacheRequest
+	32, // 35: popcorn.CompilationService.GetCachedObject:input_type -> popcorn.GetCachedObjectRequest
+	34, // 36: popcorn.CompilationService.PutCachedObject:input_type -> popcorn.PutCachedObjectRequest
+	9,  // 37: popcorn.CompilationService.StartCompilationSession:output_type -> popcorn.StartCompilationSessionReply
+	11, // 38: popcorn.CompilationService.TransferFile:output_type -> popcorn.TransferFileReply
+	13, // 39: popcorn.CompilationService.CompileSource:output_type -> popcorn.CompileSourceReply
+	15, // 40: popcorn.CompilationService.CloseSession:output_type -> popcorn.CloseSessionReply
+	17, // 41: popcorn.CompilationService.Status:output_type -> popcorn.StatusReply
+	20, // 42: popcorn.CompilationService.Drain:output_type -> popcorn.DrainReply
+	23, // 43: popcorn.CompilationService.GetCacheStats:output_type -> popcorn.GetCacheStatsReply
+	27, // 44: popcorn.CompilationService.ListCacheEntries:output_type -> popcorn.ListCacheEntriesReply
+	29, // 45: popcorn.CompilationService.EvictCacheEntries:output_type -> popcorn.EvictCacheEntriesReply
+	31, // 46: popcorn.CompilationService.ResizeCache:output_type -> popcorn.ResizeCacheReply
+	33, // 47: popcorn.CompilationService.GetCachedObject:output_type -> popcorn.GetCachedObjectReply
+	35, // 48: popcorn.CompilationService.PutCachedObject:output_type -> popcorn.PutCachedObjectReply
+	37, // [37:49] is the sub-list for method output_type
+	25, // [25:37] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_v1_compilation_server_proto_init() }
@@ -1380,7 +3159,7 @@ func file_api_proto_v1_compilation_server_proto_init() {
 			}
 		}
 		file_api_proto_v1_compilation_server_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TransferFileRequest_StreamHeader); i {
+			switch v := v.(*CompilerInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1392,6 +3171,222 @@ func file_api_proto_v1_compilation_server_proto_init() {
 			}
 		}
 		file_api_proto_v1_compilation_server_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DrainRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DrainReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCacheStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCacheStatsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheEntriesFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListCacheEntriesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListCacheEntriesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvictCacheEntriesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvictCacheEntriesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResizeCacheRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResizeCacheReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCachedObjectRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCachedObjectReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutCachedObjectRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutCachedObjectReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferFileRequest_StreamHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CompileSourceReply_StreamEpilogue); i {
 			case 0:
 				return &v.state
@@ -1403,6 +3398,18 @@ func file_api_proto_v1_compilation_server_proto_init() {
 				return nil
 			}
 		}
+		file_api_proto_v1_compilation_server_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutCachedObjectRequest_StreamHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_api_proto_v1_compilation_server_proto_msgTypes[5].OneofWrappers = []interface{}{
 		(*TransferFileRequest_Header)(nil),
@@ -1412,13 +3419,17 @@ func file_api_proto_v1_compilation_server_proto_init() {
 		(*CompileSourceReply_CompiledObjChunk)(nil),
 		(*CompileSourceReply_Epilogue)(nil),
 	}
+	file_api_proto_v1_compilation_server_proto_msgTypes[29].OneofWrappers = []interface{}{
+		(*PutCachedObjectRequest_Header)(nil),
+		(*PutCachedObjectRequest_ObjChunk)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_proto_v1_compilation_server_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   15,
+			NumEnums:      5,
+			NumMessages:   34,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
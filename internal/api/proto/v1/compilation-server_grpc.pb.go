@@ -25,6 +25,15 @@ type CompilationServiceClient interface {
 	CloseSession(ctx context.Context, in *CloseSessionRequest, opts ...grpc.CallOption) (*CloseSessionReply, error)
 	// Service api
 	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	// Admin api
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainReply, error)
+	GetCacheStats(ctx context.Context, in *GetCacheStatsRequest, opts ...grpc.CallOption) (*GetCacheStatsReply, error)
+	ListCacheEntries(ctx context.Context, in *ListCacheEntriesRequest, opts ...grpc.CallOption) (*ListCacheEntriesReply, error)
+	EvictCacheEntries(ctx context.Context, in *EvictCacheEntriesRequest, opts ...grpc.CallOption) (*EvictCacheEntriesReply, error)
+	ResizeCache(ctx context.Context, in *ResizeCacheRequest, opts ...grpc.CallOption) (*ResizeCacheReply, error)
+	// Peer api
+	GetCachedObject(ctx context.Context, in *GetCachedObjectRequest, opts ...grpc.CallOption) (CompilationService_GetCachedObjectClient, error)
+	PutCachedObject(ctx context.Context, opts ...grpc.CallOption) (CompilationService_PutCachedObjectClient, error)
 }
 
 type compilationServiceClient struct {
@@ -125,6 +134,117 @@ func (c *compilationServiceClient) Status(ctx context.Context, in *StatusRequest
 	return out, nil
 }
 
+func (c *compilationServiceClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainReply, error) {
+	out := new(DrainReply)
+	err := c.cc.Invoke(ctx, "/popcorn.CompilationService/Drain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *compilationServiceClient) GetCacheStats(ctx context.Context, in *GetCacheStatsRequest, opts ...grpc.CallOption) (*GetCacheStatsReply, error) {
+	out := new(GetCacheStatsReply)
+	err := c.cc.Invoke(ctx, "/popcorn.CompilationService/GetCacheStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *compilationServiceClient) ListCacheEntries(ctx context.Context, in *ListCacheEntriesRequest, opts ...grpc.CallOption) (*ListCacheEntriesReply, error) {
+	out := new(ListCacheEntriesReply)
+	err := c.cc.Invoke(ctx, "/popcorn.CompilationService/ListCacheEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *compilationServiceClient) EvictCacheEntries(ctx context.Context, in *EvictCacheEntriesRequest, opts ...grpc.CallOption) (*EvictCacheEntriesReply, error) {
+	out := new(EvictCacheEntriesReply)
+	err := c.cc.Invoke(ctx, "/popcorn.CompilationService/EvictCacheEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *compilationServiceClient) ResizeCache(ctx context.Context, in *ResizeCacheRequest, opts ...grpc.CallOption) (*ResizeCacheReply, error) {
+	out := new(ResizeCacheReply)
+	err := c.cc.Invoke(ctx, "/popcorn.CompilationService/ResizeCache", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *compilationServiceClient) GetCachedObject(ctx context.Context, in *GetCachedObjectRequest, opts ...grpc.CallOption) (CompilationService_GetCachedObjectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CompilationService_ServiceDesc.Streams[2], "/popcorn.CompilationService/GetCachedObject", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &compilationServiceGetCachedObjectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CompilationService_GetCachedObjectClient interface {
+	Recv() (*GetCachedObjectReply, error)
+	grpc.ClientStream
+}
+
+type compilationServiceGetCachedObjectClient struct {
+	grpc.ClientStream
+}
+
+func (x *compilationServiceGetCachedObjectClient) Recv() (*GetCachedObjectReply, error) {
+	m := new(GetCachedObjectReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *compilationServiceClient) PutCachedObject(ctx context.Context, opts ...grpc.CallOption) (CompilationService_PutCachedObjectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CompilationService_ServiceDesc.Streams[3], "/popcorn.CompilationService/PutCachedObject", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &compilationServicePutCachedObjectClient{stream}
+	return x, nil
+}
+
+type CompilationService_PutCachedObjectClient interface {
+	Send(*PutCachedObjectRequest) error
+	CloseAndRecv() (*PutCachedObjectReply, error)
+	grpc.ClientStream
+}
+
+type compilationServicePutCachedObjectClient struct {
+	grpc.ClientStream
+}
+
+func (x *compilationServicePutCachedObjectClient) Send(m *PutCachedObjectRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *compilationServicePutCachedObjectClient) CloseAndRecv() (*PutCachedObjectReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PutCachedObjectReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CompilationServiceServer is the server API for CompilationService service.
 // All implementations must embed UnimplementedCompilationServiceServer
 // for forward compatibility
@@ -136,6 +256,15 @@ type CompilationServiceServer interface {
 	CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionReply, error)
 	// Service api
 	Status(context.Context, *StatusRequest) (*StatusReply, error)
+	// Admin api
+	Drain(context.Context, *DrainRequest) (*DrainReply, error)
+	GetCacheStats(context.Context, *GetCacheStatsRequest) (*GetCacheStatsReply, error)
+	ListCacheEntries(context.Context, *ListCacheEntriesRequest) (*ListCacheEntriesReply, error)
+	EvictCacheEntries(context.Context, *EvictCacheEntriesRequest) (*EvictCacheEntriesReply, error)
+	ResizeCache(context.Context, *ResizeCacheRequest) (*ResizeCacheReply, error)
+	// Peer api
+	GetCachedObject(*GetCachedObjectRequest, CompilationService_GetCachedObjectServer) error
+	PutCachedObject(CompilationService_PutCachedObjectServer) error
 	mustEmbedUnimplementedCompilationServiceServer()
 }
 
@@ -158,6 +287,27 @@ func (UnimplementedCompilationServiceServer) CloseSession(context.Context, *Clos
 func (UnimplementedCompilationServiceServer) Status(context.Context, *StatusRequest) (*StatusReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedCompilationServiceServer) Drain(context.Context, *DrainRequest) (*DrainReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedCompilationServiceServer) GetCacheStats(context.Context, *GetCacheStatsRequest) (*GetCacheStatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCacheStats not implemented")
+}
+func (UnimplementedCompilationServiceServer) ListCacheEntries(context.Context, *ListCacheEntriesRequest) (*ListCacheEntriesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCacheEntries not implemented")
+}
+func (UnimplementedCompilationServiceServer) EvictCacheEntries(context.Context, *EvictCacheEntriesRequest) (*EvictCacheEntriesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvictCacheEntries not implemented")
+}
+func (UnimplementedCompilationServiceServer) ResizeCache(context.Context, *ResizeCacheRequest) (*ResizeCacheReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResizeCache not implemented")
+}
+func (UnimplementedCompilationServiceServer) GetCachedObject(*GetCachedObjectRequest, CompilationService_GetCachedObjectServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetCachedObject not implemented")
+}
+func (UnimplementedCompilationServiceServer) PutCachedObject(CompilationService_PutCachedObjectServer) error {
+	return status.Errorf(codes.Unimplemented, "method PutCachedObject not implemented")
+}
 func (UnimplementedCompilationServiceServer) mustEmbedUnimplementedCompilationServiceServer() {}
 
 // UnsafeCompilationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -272,6 +422,143 @@ func _CompilationService_Status_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CompilationService_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompilationServiceServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/popcorn.CompilationService/Drain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompilationServiceServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CompilationService_GetCacheStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCacheStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompilationServiceServer).GetCacheStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/popcorn.CompilationService/GetCacheStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompilationServiceServer).GetCacheStats(ctx, req.(*GetCacheStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CompilationService_ListCacheEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCacheEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompilationServiceServer).ListCacheEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/popcorn.CompilationService/ListCacheEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompilationServiceServer).ListCacheEntries(ctx, req.(*ListCacheEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CompilationService_EvictCacheEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvictCacheEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompilationServiceServer).EvictCacheEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/popcorn.CompilationService/EvictCacheEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompilationServiceServer).EvictCacheEntries(ctx, req.(*EvictCacheEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CompilationService_ResizeCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompilationServiceServer).ResizeCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/popcorn.CompilationService/ResizeCache",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompilationServiceServer).ResizeCache(ctx, req.(*ResizeCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CompilationService_GetCachedObject_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetCachedObjectRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CompilationServiceServer).GetCachedObject(m, &compilationServiceGetCachedObjectServer{stream})
+}
+
+type CompilationService_GetCachedObjectServer interface {
+	Send(*GetCachedObjectReply) error
+	grpc.ServerStream
+}
+
+type compilationServiceGetCachedObjectServer struct {
+	grpc.ServerStream
+}
+
+func (x *compilationServiceGetCachedObjectServer) Send(m *GetCachedObjectReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CompilationService_PutCachedObject_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CompilationServiceServer).PutCachedObject(&compilationServicePutCachedObjectServer{stream})
+}
+
+type CompilationService_PutCachedObjectServer interface {
+	SendAndClose(*PutCachedObjectReply) error
+	Recv() (*PutCachedObjectRequest, error)
+	grpc.ServerStream
+}
+
+type compilationServicePutCachedObjectServer struct {
+	grpc.ServerStream
+}
+
+func (x *compilationServicePutCachedObjectServer) SendAndClose(m *PutCachedObjectReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *compilationServicePutCachedObjectServer) Recv() (*PutCachedObjectRequest, error) {
+	m := new(PutCachedObjectRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CompilationService_ServiceDesc is the grpc.ServiceDesc for CompilationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -291,6 +578,26 @@ var CompilationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _CompilationService_Status_Handler,
 		},
+		{
+			MethodName: "Drain",
+			Handler:    _CompilationService_Drain_Handler,
+		},
+		{
+			MethodName: "GetCacheStats",
+			Handler:    _CompilationService_GetCacheStats_Handler,
+		},
+		{
+			MethodName: "ListCacheEntries",
+			Handler:    _CompilationService_ListCacheEntries_Handler,
+		},
+		{
+			MethodName: "EvictCacheEntries",
+			Handler:    _CompilationService_EvictCacheEntries_Handler,
+		},
+		{
+			MethodName: "ResizeCache",
+			Handler:    _CompilationService_ResizeCache_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -304,6 +611,16 @@ var CompilationService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _CompilationService_CompileSource_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "GetCachedObject",
+			Handler:       _CompilationService_GetCachedObject_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PutCachedObject",
+			Handler:       _CompilationService_PutCachedObject_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "api/proto/v1/compilation-server.proto",
 }
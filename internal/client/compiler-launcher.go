@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"hash/fnv"
 	"os"
@@ -8,6 +9,8 @@ import (
 
 	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
 	"github.com/AlexK0/popcorn/internal/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // ErrNoAvailableHosts ...
@@ -42,30 +45,56 @@ func chooseServerNumber(localCompiler *LocalCompiler, hostsCount int) int {
 	return int(hasher.Sum32()) % hostsCount
 }
 
-func tryRemoteCompilation(localCompiler *LocalCompiler, settings *Settings) (retCode int, stdout []byte, stderr []byte, err error) {
+func tryRemoteCompilation(ctx context.Context, localCompiler *LocalCompiler, settings *Settings, span *common.Span) (retCode int, stdout []byte, stderr []byte, err error) {
 	hostsCount := len(settings.Servers)
 	if hostsCount == 0 {
 		return 0, nil, nil, ErrNoAvailableHosts
 	}
 
+	collectSpan := span.StartChild("CollectFiles", common.SpanKindInternal)
 	files, err := localCompiler.CollectFilesAndUpdateIncludeDirs()
+	collectSpan.SetAttribute("files.count", len(files))
+	collectSpan.SetError(err)
+	collectSpan.End()
 	if err != nil {
 		return 0, nil, nil, err
 	}
 
+	metaSpan := span.StartChild("ReadFilesMeta", common.SpanKindInternal)
 	filesMeta, err := readFilesMeta(files)
+	metaSpan.SetError(err)
+	metaSpan.End()
 	if err != nil {
 		return 0, nil, nil, err
 	}
 
-	remoteServer := settings.Servers[chooseServerNumber(localCompiler, hostsCount)]
-	remoteCompiler, err := MakeRemoteCompiler(localCompiler, remoteServer)
+	// Unavailable servers (e.g. draining ones) are skipped, the compilation goes to the next server
+	serverNumber := chooseServerNumber(localCompiler, hostsCount)
+	for attempt := 0; ; attempt++ {
+		remoteServer := settings.Servers[(serverNumber+attempt)%hostsCount]
+		remoteSpan := span.StartChild("RemoteCompilation", common.SpanKindInternal)
+		remoteSpan.SetAttribute("server", remoteServer)
+		retCode, stdout, stderr, err = compileOnServer(ctx, localCompiler, filesMeta, remoteServer, settings, remoteSpan)
+		remoteSpan.SetError(err)
+		remoteSpan.End()
+		if attempt+1 == hostsCount || status.Code(err) != codes.Unavailable {
+			return retCode, stdout, stderr, err
+		}
+		common.MakeLogger("",
+			common.LogField{Key: common.LogFieldSource, Value: localCompiler.inFile},
+			common.LogField{Key: common.LogFieldServer, Value: remoteServer}).Warning("Server is unavailable:", err)
+	}
+}
+
+func compileOnServer(ctx context.Context, localCompiler *LocalCompiler, filesMeta []*pb.FileMetadata, remoteServer string, settings *Settings, span *common.Span) (retCode int, stdout []byte, stderr []byte, err error) {
+	remoteCompiler, err := MakeRemoteCompiler(ctx, localCompiler, remoteServer, settings)
 	if err != nil {
 		return 0, nil, nil, err
 	}
 	defer remoteCompiler.Clear()
+	remoteCompiler.span = span
 
-	if err = remoteCompiler.SetupEnvironment(filesMeta, settings.UseObjCache); err != nil {
+	if err = remoteCompiler.SetupEnvironment(filesMeta, settings.ObjCacheMode, settings.Priority); err != nil {
 		return 0, nil, nil, err
 	}
 
@@ -75,14 +104,44 @@ func tryRemoteCompilation(localCompiler *LocalCompiler, settings *Settings) (ret
 // PerformCompilation ...
 func PerformCompilation(compilerCmdLine []string, settings *Settings) (retCode int, stdout []byte, stderr []byte) {
 	localCompiler := MakeLocalCompiler(compilerCmdLine)
+	log := common.MakeLogger("", common.LogField{Key: common.LogFieldSource, Value: localCompiler.inFile})
+	tracer := common.MakeTracer("popcorn-client", settings.TraceDir, settings.TraceURL)
+	span := tracer.StartSpan("Compile", common.SpanKindInternal, common.ParseTraceParent(settings.TraceParent))
+	span.SetAttribute("compiler", localCompiler.name)
+	span.SetAttribute("source", localCompiler.inFile)
+	defer func() {
+		span.SetAttribute("compiler.exit_code", retCode)
+		span.End()
+		if err := tracer.Flush(); err != nil {
+			log.Warning("Can't export traces:", err)
+		}
+	}()
+
+	// ctx is cancelled if the client is interrupted while the job slot is lent
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	localJobs := MakeLocalJobs(settings)
+	defer localJobs.Finish()
 	if localCompiler.RemoteCompilationAllowed {
-		common.LogInfo("Trying remote compilaton")
-		retCode, stdout, stderr, err := tryRemoteCompilation(localCompiler, settings)
+		localJobs.LendSlotForRemoteCompilation(cancel)
+		log.Info("Trying remote compilaton")
+		retCode, stdout, stderr, err := tryRemoteCompilation(ctx, localCompiler, settings, span)
+		if sig := localJobs.Interrupted(); sig != 0 {
+			log.Warning("Interrupted by", sig)
+			return 128 + int(sig), nil, nil
+		}
 		if err == nil {
 			return retCode, stdout, stderr
 		}
-		common.LogError("Can't compile remotely:", err)
+		log.Error("Can't compile remotely:", err)
 	}
 
+	localJobs.AcquireSlotForLocalCompilation()
+	if sig := localJobs.Interrupted(); sig != 0 {
+		log.Warning("Interrupted by", sig)
+		return 128 + int(sig), nil, nil
+	}
+	localSpan := span.StartChild("LocalCompilation", common.SpanKindInternal)
+	defer localSpan.End()
 	return localCompiler.CompileLocally()
 }
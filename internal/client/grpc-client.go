@@ -2,11 +2,16 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"time"
 
 	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
 	"github.com/AlexK0/popcorn/internal/common"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // GRPCClient ...
@@ -17,21 +22,62 @@ type GRPCClient struct {
 	Client      pb.CompilationServiceClient
 }
 
-// MakeGRPCClient ...
-func MakeGRPCClient(serverHostPort string) (*GRPCClient, error) {
-	connectionContext, connectionCancel := context.WithTimeout(context.Background(), time.Second*3)
-	defer connectionCancel()
-	connection, err := grpc.DialContext(
-		connectionContext,
-		serverHostPort,
-		grpc.WithInsecure(),
+func makeTransportDialOption(settings *Settings) (grpc.DialOption, error) {
+	if !settings.UseTLS {
+		return grpc.WithInsecure(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: settings.TLSServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(settings.TLSCAFile) != 0 {
+		caPEM, err := ioutil.ReadFile(settings.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read server CA: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("No certificates found in server CA %q", settings.TLSCAFile)
+		}
+	}
+	if len(settings.TLSCertFile) != 0 {
+		certificate, err := tls.LoadX509KeyPair(settings.TLSCertFile, settings.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// MakeGRPCClient connects to the server, the calls are cancelled with the parent context.
+func MakeGRPCClient(parentCtx context.Context, serverHostPort string, settings *Settings) (*GRPCClient, error) {
+	transportOption, err := makeTransportDialOption(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	dialOptions := []grpc.DialOption{
+		transportOption,
 		grpc.WithBlock(),
-		grpc.WithDefaultCallOptions(grpc.UseCompressor(common.ZstdCompressorName)))
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(common.ZstdCompressorName)),
+	}
+	if len(settings.AuthToken) != 0 {
+		if !settings.UseTLS {
+			return nil, fmt.Errorf("Auth token can't be sent without TLS, set POPCORN_TLS or POPCORN_TLS_CA_FILE")
+		}
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(common.MakeBearerTokenCredentials(settings.AuthToken)))
+	}
+
+	connectionContext, connectionCancel := context.WithTimeout(parentCtx, time.Second*3)
+	defer connectionCancel()
+	connection, err := grpc.DialContext(connectionContext, serverHostPort, dialOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute*5)
+	ctx, cancelFunc := context.WithTimeout(parentCtx, time.Minute*5)
 	return &GRPCClient{
 		Connection:  connection,
 		CallContext: ctx,
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// jobserver is a client of the GNU make jobserver protocol.
+// Ninja (1.13+) exports the same protocol through MAKEFLAGS with a fifo.
+type jobserver struct {
+	readFd  int
+	writeFd int
+	fifo    *os.File
+}
+
+func isFifo(fd int) bool {
+	var stat syscall.Stat_t
+	if err := syscall.Fstat(fd, &stat); err != nil {
+		return false
+	}
+	return stat.Mode&syscall.S_IFMT == syscall.S_IFIFO
+}
+
+func parseJobserverAuth(makeFlags string) string {
+	auth := ""
+	for _, flag := range strings.Fields(makeFlags) {
+		if flag == "--" {
+			break
+		}
+		if strings.HasPrefix(flag, "--jobserver-auth=") {
+			auth = flag[len("--jobserver-auth="):]
+		} else if strings.HasPrefix(flag, "--jobserver-fds=") {
+			auth = flag[len("--jobserver-fds="):]
+		}
+	}
+	return auth
+}
+
+func openJobserver(makeFlags string) (*jobserver, error) {
+	auth := parseJobserverAuth(makeFlags)
+	if len(auth) == 0 {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(auth, "fifo:") {
+		fifo, err := os.OpenFile(auth[len("fifo:"):], os.O_RDWR, 0)
+		if err != nil {
+			return nil, fmt.Errorf("Can't open jobserver fifo: %v", err)
+		}
+		fd := int(fifo.Fd())
+		return &jobserver{readFd: fd, writeFd: fd, fifo: fifo}, nil
+	}
+
+	fds := strings.Split(auth, ",")
+	if len(fds) != 2 {
+		return nil, fmt.Errorf("Unexpected jobserver auth %q", auth)
+	}
+	readFd, errRead := strconv.Atoi(fds[0])
+	writeFd, errWrite := strconv.Atoi(fds[1])
+	if errRead != nil || errWrite != nil || readFd < 0 || writeFd < 0 {
+		return nil, fmt.Errorf("Unexpected jobserver auth %q", auth)
+	}
+	// make closes the jobserver pipe for recipes which are not marked as recursive,
+	// and these descriptors can be already reused by the go runtime.
+	if !isFifo(readFd) || !isFifo(writeFd) {
+		return nil, fmt.Errorf("Jobserver file descriptors %q are not available", auth)
+	}
+	return &jobserver{readFd: readFd, writeFd: writeFd}, nil
+}
+
+func (js *jobserver) putToken() error {
+	for {
+		_, err := syscall.Write(js.writeFd, []byte{'+'})
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func (js *jobserver) takeToken() error {
+	var token [1]byte
+	for {
+		n, err := syscall.Read(js.readFd, token[:])
+		if err == nil && n == 1 {
+			return nil
+		}
+		if err == syscall.EAGAIN {
+			// make may share a nonblocking pipe with its children
+			time.Sleep(10 * time.Millisecond)
+		} else if err != nil && err != syscall.EINTR {
+			return err
+		} else if err == nil {
+			return fmt.Errorf("Jobserver pipe is closed")
+		}
+	}
+}
+
+func (js *jobserver) close() {
+	if js.fifo != nil {
+		js.fifo.Close()
+	}
+}
+
+// LocalJobs limits amount of compilers launched locally on the machine.
+// Under GNU make (or Ninja) jobserver popcorn-client already holds the implicit job slot.
+// The slot is lent back to the jobserver while the source is compiled remotely,
+// so remote compilations run freely and local fallbacks take the slot back before launching a compiler.
+// Without jobserver a machine-wide semaphore built on lock files is used.
+type LocalJobs struct {
+	jobserver   *jobserver
+	slotLent    bool
+	signals     chan os.Signal
+	interrupted int32
+
+	lockDir  string
+	limit    int
+	lockFile *os.File
+}
+
+// MakeLocalJobs ...
+func MakeLocalJobs(settings *Settings) *LocalJobs {
+	localJobs := &LocalJobs{
+		lockDir: settings.LocalJobsLockDir,
+		limit:   settings.LocalJobsLimit,
+	}
+	jobserver, err := openJobserver(os.Getenv("MAKEFLAGS"))
+	if err != nil {
+		common.LogWarning("Can't use jobserver:", err)
+	}
+	localJobs.jobserver = jobserver
+	return localJobs
+}
+
+// LendSlotForRemoteCompilation returns the slot to the jobserver, interrupt is called if the client gets a signal meanwhile.
+func (localJobs *LocalJobs) LendSlotForRemoteCompilation(interrupt func()) {
+	if localJobs.jobserver == nil || localJobs.slotLent {
+		return
+	}
+	localJobs.catchSignals(interrupt)
+	if err := localJobs.jobserver.putToken(); err != nil {
+		common.LogWarning("Can't return token to jobserver:", err)
+		localJobs.releaseSignals()
+		return
+	}
+	localJobs.slotLent = true
+}
+
+// catchSignals keeps the client alive on signals while the slot is lent (e.g. make sends SIGINT to its jobs on ^C),
+// so the deferred cleanup takes the token back, otherwise the jobserver pool keeps an extra token for the rest of the build.
+func (localJobs *LocalJobs) catchSignals(interrupt func()) {
+	localJobs.signals = make(chan os.Signal, 1)
+	signal.Notify(localJobs.signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func(signals chan os.Signal) {
+		if sig, ok := <-signals; ok {
+			atomic.StoreInt32(&localJobs.interrupted, int32(sig.(syscall.Signal)))
+			interrupt()
+		}
+	}(localJobs.signals)
+}
+
+// releaseSignals restores the default handling of signals.
+func (localJobs *LocalJobs) releaseSignals() {
+	if localJobs.signals != nil {
+		signal.Stop(localJobs.signals)
+		close(localJobs.signals)
+		localJobs.signals = nil
+	}
+}
+
+// Interrupted returns the signal which the client got while the slot was lent, zero if there is no such signal.
+func (localJobs *LocalJobs) Interrupted() syscall.Signal {
+	return syscall.Signal(atomic.LoadInt32(&localJobs.interrupted))
+}
+
+func (localJobs *LocalJobs) reclaimSlot() {
+	if !localJobs.slotLent {
+		return
+	}
+	localJobs.slotLent = false
+	if localJobs.Interrupted() == 0 {
+		if err := localJobs.jobserver.takeToken(); err != nil {
+			common.LogWarning("Can't take token from jobserver:", err)
+		}
+		return
+	}
+	// The token may be never released if the whole build is interrupted, so the client doesn't wait for it too long
+	reclaimed := make(chan error, 1)
+	go func() { reclaimed <- localJobs.jobserver.takeToken() }()
+	select {
+	case err := <-reclaimed:
+		if err != nil {
+			common.LogWarning("Can't take token from jobserver:", err)
+		}
+	case <-time.After(time.Second):
+		common.LogWarning("Can't take token from jobserver in time after interruption")
+	}
+}
+
+func (localJobs *LocalJobs) lockSlot() error {
+	if err := os.MkdirAll(localJobs.lockDir, os.ModePerm); err != nil {
+		return err
+	}
+	// The directory is shared between all users of the machine
+	_ = os.Chmod(localJobs.lockDir, os.ModePerm|os.ModeSticky)
+
+	firstSlot := os.Getpid()
+	for {
+		for i := 0; i < localJobs.limit; i++ {
+			slotPath := path.Join(localJobs.lockDir, fmt.Sprintf("slot-%d", (firstSlot+i)%localJobs.limit))
+			lockFile, err := os.OpenFile(slotPath, os.O_RDONLY|os.O_CREATE, 0666)
+			if err != nil {
+				return err
+			}
+			if err = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err == nil {
+				localJobs.lockFile = lockFile
+				return nil
+			}
+			lockFile.Close()
+			if err != syscall.EWOULDBLOCK && err != syscall.EINTR {
+				return err
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// AcquireSlotForLocalCompilation ...
+func (localJobs *LocalJobs) AcquireSlotForLocalCompilation() {
+	if localJobs.jobserver != nil {
+		localJobs.reclaimSlot()
+		// The slot is held, signals may kill the client as usual
+		localJobs.releaseSignals()
+		return
+	}
+	if localJobs.limit <= 0 || localJobs.lockFile != nil {
+		return
+	}
+	if err := localJobs.lockSlot(); err != nil {
+		common.LogWarning("Can't acquire local job slot:", err)
+	}
+}
+
+// Finish ...
+func (localJobs *LocalJobs) Finish() {
+	if localJobs.jobserver != nil {
+		localJobs.reclaimSlot()
+		localJobs.releaseSignals()
+		localJobs.jobserver.close()
+		localJobs.jobserver = nil
+	}
+	if localJobs.lockFile != nil {
+		localJobs.lockFile.Close()
+		localJobs.lockFile = nil
+	}
+}
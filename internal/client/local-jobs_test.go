@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+)
+
+func TestParseJobserverAuth(t *testing.T) {
+	tests := []struct {
+		makeFlags string
+		auth      string
+	}{
+		{"", ""},
+		{"-j4", ""},
+		{"-j4 --jobserver-auth=3,4", "3,4"},
+		{" -j --jobserver-fds=5,6", "5,6"},
+		{"-j8 --jobserver-auth=fifo:/tmp/GMfifo123", "fifo:/tmp/GMfifo123"},
+		// The last option wins, make may pass both forms
+		{"--jobserver-fds=3,4 --jobserver-auth=fifo:/tmp/fifo", "fifo:/tmp/fifo"},
+		// Variables after -- are not options
+		{"-j2 -- --jobserver-auth=3,4", ""},
+		{"kw -- CFLAGS=--jobserver-auth=3,4", ""},
+	}
+	for _, test := range tests {
+		if auth := parseJobserverAuth(test.makeFlags); auth != test.auth {
+			t.Errorf("parseJobserverAuth(%q) = %q, expected %q", test.makeFlags, auth, test.auth)
+		}
+	}
+}
+
+func checkJobserverTokens(t *testing.T, js *jobserver) {
+	if err := js.putToken(); err != nil {
+		t.Fatalf("Can't put token: %v", err)
+	}
+	if err := js.takeToken(); err != nil {
+		t.Fatalf("Can't take token: %v", err)
+	}
+}
+
+func TestOpenJobserverPipe(t *testing.T) {
+	var fds [2]int
+	if err := syscall.Pipe(fds[:]); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	js, err := openJobserver(fmt.Sprintf("-j2 --jobserver-auth=%d,%d", fds[0], fds[1]))
+	if err != nil {
+		t.Fatalf("Can't open jobserver: %v", err)
+	}
+	if js.readFd != fds[0] || js.writeFd != fds[1] {
+		t.Fatalf("Unexpected jobserver fds %d,%d, expected %d,%d", js.readFd, js.writeFd, fds[0], fds[1])
+	}
+	checkJobserverTokens(t, js)
+	js.close()
+}
+
+func TestOpenJobserverFifo(t *testing.T) {
+	fifoPath := path.Join(t.TempDir(), "jobserver")
+	if err := syscall.Mkfifo(fifoPath, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	js, err := openJobserver("-j2 --jobserver-auth=fifo:" + fifoPath)
+	if err != nil {
+		t.Fatalf("Can't open jobserver: %v", err)
+	}
+	defer js.close()
+	if js.fifo == nil || js.readFd != js.writeFd {
+		t.Fatalf("Jobserver fifo is expected")
+	}
+	checkJobserverTokens(t, js)
+}
+
+func TestOpenJobserverErrors(t *testing.T) {
+	file, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, makeFlags := range []string{
+		"--jobserver-auth=3",
+		"--jobserver-auth=3,4,5",
+		"--jobserver-auth=a,b",
+		"--jobserver-auth=-1,4",
+		// make closes the pipe for non recursive recipes, the descriptors may be reused by other files
+		fmt.Sprintf("--jobserver-auth=%d,%d", file.Fd(), file.Fd()),
+		"--jobserver-auth=fifo:" + path.Join(t.TempDir(), "missing"),
+	} {
+		if js, err := openJobserver(makeFlags); err == nil {
+			js.close()
+			t.Errorf("openJobserver(%q) is expected to fail", makeFlags)
+		}
+	}
+
+	if js, err := openJobserver("-j4"); js != nil || err != nil {
+		t.Errorf("No jobserver is expected without auth, got %v, %v", js, err)
+	}
+}
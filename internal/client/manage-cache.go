@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// CacheCommandOptions are the arguments of the server cache commands.
+type CacheCommandOptions struct {
+	Cache           string
+	FileNamePattern string
+	OlderThan       time.Duration
+	Key             string
+	All             bool
+	Limit           int64
+	SizeLimit       int64
+}
+
+func parseCacheType(cache string) (pb.CacheType, error) {
+	switch cache {
+	case "obj":
+		return pb.CacheType_CACHE_OBJ, nil
+	case "src":
+		return pb.CacheType_CACHE_SRC, nil
+	}
+	return 0, fmt.Errorf("Unknown cache %q, obj or src is expected", cache)
+}
+
+func makeCacheEntriesFilter(options *CacheCommandOptions) (*pb.CacheEntriesFilter, error) {
+	cacheType, err := parseCacheType(options.Cache)
+	if err != nil {
+		return nil, err
+	}
+	filter := &pb.CacheEntriesFilter{
+		Cache:           cacheType,
+		FileNamePattern: options.FileNamePattern,
+		MinAge:          int64(options.OlderThan),
+	}
+	if len(options.Key) != 0 {
+		key, err := common.MakeSHA256StructFromHex(options.Key)
+		if err != nil {
+			return nil, err
+		}
+		filter.Key = common.SHA256StructToSHA256Message(key)
+	}
+	return filter, nil
+}
+
+func printCacheStats(reply *pb.GetCacheStatsReply) {
+	for _, cacheStats := range reply.Caches {
+		fmt.Printf("  %s:", cacheStats.Cache)
+		if cacheStats.Remote {
+			fmt.Printf(" remote, %d uploaded files\n", cacheStats.FilesCount)
+			continue
+		}
+		fmt.Printf(" %d files, %d bytes on disk of %d limit, %d original bytes, %d purged files, %d hits, %d misses\n",
+			cacheStats.FilesCount, cacheStats.BytesOnDisk, cacheStats.SizeLimit, cacheStats.OriginalBytes, cacheStats.PurgedFiles,
+			cacheStats.Hits, cacheStats.Misses)
+	}
+}
+
+func printCacheEntries(reply *pb.ListCacheEntriesReply) {
+	for _, entry := range reply.Entries {
+		key := common.SHA256MessageToSHA256Struct(entry.Key)
+		fmt.Printf("  %s %s %d bytes (%d original), used %s ago\n",
+			key.ToHex(), entry.FileName, entry.FileSize, entry.OriginalSize, time.Duration(entry.Age).Truncate(time.Second))
+	}
+	fmt.Printf("  Shown %d of %d matched entries\n", len(reply.Entries), reply.MatchedEntries)
+}
+
+func runCacheCommand(serverHostPort string, settings *Settings, command string, options *CacheCommandOptions) error {
+	grpcClient, err := MakeGRPCClient(context.Background(), serverHostPort, settings)
+	if err != nil {
+		return err
+	}
+	defer grpcClient.Clear()
+
+	switch command {
+	case "stats":
+		reply, err := grpcClient.Client.GetCacheStats(grpcClient.CallContext, &pb.GetCacheStatsRequest{})
+		if err != nil {
+			return err
+		}
+		printCacheStats(reply)
+	case "list":
+		filter, err := makeCacheEntriesFilter(options)
+		if err != nil {
+			return err
+		}
+		reply, err := grpcClient.Client.ListCacheEntries(grpcClient.CallContext, &pb.ListCacheEntriesRequest{Filter: filter, Limit: options.Limit})
+		if err != nil {
+			return err
+		}
+		printCacheEntries(reply)
+	case "evict":
+		filter, err := makeCacheEntriesFilter(options)
+		if err != nil {
+			return err
+		}
+		reply, err := grpcClient.Client.EvictCacheEntries(grpcClient.CallContext, &pb.EvictCacheEntriesRequest{Filter: filter, All: options.All})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  Evicted %d files, %d bytes\n", reply.EvictedFiles, reply.EvictedBytes)
+	case "resize":
+		cacheType, err := parseCacheType(options.Cache)
+		if err != nil {
+			return err
+		}
+		if options.SizeLimit < 0 {
+			return fmt.Errorf("Cache size limit is required")
+		}
+		reply, err := grpcClient.Client.ResizeCache(grpcClient.CallContext, &pb.ResizeCacheRequest{Cache: cacheType, SizeLimit: options.SizeLimit})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  Limit is changed from %d to %d bytes, %d files purged\n", reply.PreviousSizeLimit, options.SizeLimit, reply.PurgedFiles)
+	default:
+		return fmt.Errorf("Unknown cache command %q, stats, list, evict or resize is expected", command)
+	}
+	return nil
+}
+
+// ManageServersCache runs the cache command on the servers, requires admin permission.
+func ManageServersCache(settings *Settings, servers []string, command string, options *CacheCommandOptions) bool {
+	ok := true
+	for _, serverHostPort := range servers {
+		fmt.Printf("Server \033[36m%s\033[0m:\n", serverHostPort)
+		if err := runCacheCommand(serverHostPort, settings, command, options); err != nil {
+			fmt.Println("  \033[31mError:\033[0m", err)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -1,7 +1,11 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
@@ -14,45 +18,225 @@ type checkServerRes struct {
 	processingTime time.Duration
 }
 
-func checkServer(serverHostPort string, checkCompiler string, statusChannel chan<- checkServerRes) {
+// problems returns the reasons why the server can't compile, the server is healthy if there are none.
+func (res *checkServerRes) problems() []string {
+	if res.err != nil {
+		return []string{"unavailable"}
+	}
+	var problems []string
+	if res.serverStatus.Draining {
+		problems = append(problems, "draining")
+	}
+	if res.serverStatus.CompilerVersion == "not allowed" || res.serverStatus.CompilerVersion == "unknown" {
+		problems = append(problems, "compiler is "+res.serverStatus.CompilerVersion)
+	}
+	return problems
+}
+
+func checkServer(serverHostPort string, settings *Settings, checkCompiler string, res *checkServerRes, wg *sync.WaitGroup) {
 	start := time.Now()
-	grpcClient, err := MakeGRPCClient(serverHostPort)
+	defer func() {
+		res.processingTime = time.Since(start)
+		wg.Done()
+	}()
+	res.serverHostPort = serverHostPort
+	grpcClient, err := MakeGRPCClient(context.Background(), serverHostPort, settings)
 	if err != nil {
-		statusChannel <- checkServerRes{err: err, serverHostPort: serverHostPort}
+		res.err = err
 		return
 	}
 	defer grpcClient.Clear()
 
-	serverStatus, err := grpcClient.Client.Status(grpcClient.CallContext, &pb.StatusRequest{CheckCompiler: checkCompiler})
-	statusChannel <- checkServerRes{
-		serverStatus:   serverStatus,
-		err:            err,
-		serverHostPort: serverHostPort,
-		processingTime: time.Since(start),
+	res.serverStatus, res.err = grpcClient.Client.Status(grpcClient.CallContext, &pb.StatusRequest{CheckCompiler: checkCompiler})
+}
+
+func hitRatio(hits int64, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
 	}
+	return float64(hits) / float64(hits+misses)
 }
 
-// CheckServers ...
-func CheckServers(settings *Settings, checkCompiler string) {
-	statusChannel := make(chan checkServerRes)
+func printServerStatus(res *checkServerRes) {
+	fmt.Printf("Server \033[36m%s\033[0m: ", res.serverHostPort)
+	if res.err != nil {
+		fmt.Println("\033[31munavailable\033[0m")
+		fmt.Println("  Error:", res.err)
+		return
+	}
+	if problems := res.problems(); len(problems) != 0 {
+		fmt.Printf("\033[33m%s\033[0m\n", strings.Join(problems, ", "))
+	} else {
+		fmt.Println("\033[32mok\033[0m")
+	}
+	serverStatus := res.serverStatus
+	fmt.Println("  Proceesing time:", res.processingTime.Truncate(time.Microsecond))
+	if len(serverStatus.ServerName) != 0 {
+		fmt.Println("  Server name:", serverStatus.ServerName)
+	}
+	fmt.Println("  Server uptime:", time.Duration(serverStatus.ServerUptime).Truncate(time.Second))
+	fmt.Println("  Server version:", serverStatus.ServerVersion)
+	fmt.Println("  Server args:", serverStatus.ServerArgs)
+	fmt.Println("  Compiler:", serverStatus.CompilerVersion)
+	if serverStatus.CPUCount != 0 {
+		fmt.Printf("  CPUs: %d, load average: %v\n", serverStatus.CPUCount, serverStatus.LoadAverage)
+	}
+	fmt.Printf("  Compilations: %d running of %d, %d queued, %d active sessions\n",
+		serverStatus.RunningCompilations, serverStatus.MaxParallelCompilations, serverStatus.QueuedCompilations, serverStatus.ActiveSessions)
+	for _, cacheStats := range serverStatus.Caches {
+		fmt.Printf("  %s: %d files, %d bytes on disk, hit ratio %.1f%% (%d hits, %d misses)\n",
+			cacheStats.Cache, cacheStats.FilesCount, cacheStats.BytesOnDisk,
+			100*hitRatio(cacheStats.Hits, cacheStats.Misses), cacheStats.Hits, cacheStats.Misses)
+	}
+	for _, compiler := range serverStatus.Compilers {
+		fmt.Printf("  Available compiler %s: %s\n", compiler.Compiler, compiler.Version)
+	}
+	if len(serverStatus.Capabilities) != 0 {
+		fmt.Println("  Capabilities:", strings.Join(serverStatus.Capabilities, ", "))
+	}
+}
+
+type cacheStatusJSON struct {
+	Cache         string  `json:"cache"`
+	Remote        bool    `json:"remote"`
+	FilesCount    int64   `json:"files_count"`
+	BytesOnDisk   int64   `json:"bytes_on_disk"`
+	OriginalBytes int64   `json:"original_bytes"`
+	SizeLimit     int64   `json:"size_limit"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRatio      float64 `json:"hit_ratio"`
+}
 
-	for _, serverHostPort := range settings.Servers {
-		go checkServer(serverHostPort, checkCompiler, statusChannel)
+type compilerStatusJSON struct {
+	Compiler string `json:"compiler"`
+	Version  string `json:"version"`
+}
+
+type serverStatusJSON struct {
+	ServerName              string               `json:"server_name"`
+	Version                 string               `json:"version"`
+	Args                    []string             `json:"args"`
+	Uptime                  float64              `json:"uptime_seconds"`
+	CompilerVersion         string               `json:"compiler_version"`
+	CPUCount                int32                `json:"cpu_count"`
+	LoadAverage             []float64            `json:"load_average"`
+	RunningCompilations     int64                `json:"running_compilations"`
+	QueuedCompilations      int64                `json:"queued_compilations"`
+	MaxParallelCompilations int64                `json:"max_parallel_compilations"`
+	ActiveSessions          int64                `json:"active_sessions"`
+	Draining                bool                 `json:"draining"`
+	Caches                  []cacheStatusJSON    `json:"caches"`
+	Compilers               []compilerStatusJSON `json:"compilers"`
+	Capabilities            []string             `json:"capabilities"`
+}
+
+type checkServerJSON struct {
+	Server         string            `json:"server"`
+	Healthy        bool              `json:"healthy"`
+	Problems       []string          `json:"problems,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	ProcessingTime float64           `json:"processing_time_seconds"`
+	Status         *serverStatusJSON `json:"status,omitempty"`
+}
+
+func makeCheckServerJSON(res *checkServerRes) checkServerJSON {
+	problems := res.problems()
+	result := checkServerJSON{
+		Server:         res.serverHostPort,
+		Healthy:        len(problems) == 0,
+		Problems:       problems,
+		ProcessingTime: res.processingTime.Seconds(),
+	}
+	if res.err != nil {
+		result.Error = res.err.Error()
+		return result
+	}
+
+	serverStatus := res.serverStatus
+	result.Status = &serverStatusJSON{
+		ServerName:              serverStatus.ServerName,
+		Version:                 serverStatus.ServerVersion,
+		Args:                    serverStatus.ServerArgs,
+		Uptime:                  time.Duration(serverStatus.ServerUptime).Seconds(),
+		CompilerVersion:         serverStatus.CompilerVersion,
+		CPUCount:                serverStatus.CPUCount,
+		LoadAverage:             serverStatus.LoadAverage,
+		RunningCompilations:     serverStatus.RunningCompilations,
+		QueuedCompilations:      serverStatus.QueuedCompilations,
+		MaxParallelCompilations: serverStatus.MaxParallelCompilations,
+		ActiveSessions:          serverStatus.ActiveSessions,
+		Draining:                serverStatus.Draining,
+		Caches:                  make([]cacheStatusJSON, 0, len(serverStatus.Caches)),
+		Compilers:               make([]compilerStatusJSON, 0, len(serverStatus.Compilers)),
+		Capabilities:            serverStatus.Capabilities,
+	}
+	for _, cacheStats := range serverStatus.Caches {
+		result.Status.Caches = append(result.Status.Caches, cacheStatusJSON{
+			Cache:         strings.ToLower(strings.TrimPrefix(cacheStats.Cache.String(), "CACHE_")),
+			Remote:        cacheStats.Remote,
+			FilesCount:    cacheStats.FilesCount,
+			BytesOnDisk:   cacheStats.BytesOnDisk,
+			OriginalBytes: cacheStats.OriginalBytes,
+			SizeLimit:     cacheStats.SizeLimit,
+			Hits:          cacheStats.Hits,
+			Misses:        cacheStats.Misses,
+			HitRatio:      hitRatio(cacheStats.Hits, cacheStats.Misses),
+		})
+	}
+	for _, compiler := range serverStatus.Compilers {
+		result.Status.Compilers = append(result.Status.Compilers, compilerStatusJSON{compiler.Compiler, compiler.Version})
+	}
+	return result
+}
+
+// CheckServers prints the status of the servers in the text or json format, returns false if any server is unhealthy.
+func CheckServers(settings *Settings, checkCompiler string, format string) (bool, error) {
+	if format != "text" && format != "json" {
+		return false, fmt.Errorf("Unknown format %q, text or json is expected", format)
+	}
+
+	results := make([]checkServerRes, len(settings.Servers))
+	wg := sync.WaitGroup{}
+	wg.Add(len(settings.Servers))
+	for i, serverHostPort := range settings.Servers {
+		go checkServer(serverHostPort, settings, checkCompiler, &results[i], &wg)
 	}
+	wg.Wait()
 
-	for range settings.Servers {
-		res := <-statusChannel
-		fmt.Printf("Server \033[36m%s\033[0m: ", res.serverHostPort)
-		if res.err != nil {
-			fmt.Println("\033[31munavailable\033[0m")
-			fmt.Println("  Error:", res.err)
+	healthy := true
+	jsonResults := make([]checkServerJSON, 0, len(results))
+	for i := range results {
+		healthy = healthy && len(results[i].problems()) == 0
+		if format == "json" {
+			jsonResults = append(jsonResults, makeCheckServerJSON(&results[i]))
 		} else {
-			fmt.Println("\033[32mok\033[0m")
-			fmt.Println("  Proceesing time:", res.processingTime.Truncate(time.Microsecond))
-			fmt.Println("  Server uptime:", time.Duration(res.serverStatus.ServerUptime).Truncate(time.Second))
-			fmt.Println("  Server version:", res.serverStatus.ServerVersion)
-			fmt.Println("  Server args:", res.serverStatus.ServerArgs)
-			fmt.Println("  Compiler:", res.serverStatus.CompilerVersion)
+			printServerStatus(&results[i])
 		}
 	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(jsonResults, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(string(data))
+	}
+	return healthy, nil
+}
+
+// DrainServer asks the server to stop accepting new compilations and to stop after the active ones.
+func DrainServer(settings *Settings, serverHostPort string) error {
+	grpcClient, err := MakeGRPCClient(context.Background(), serverHostPort, settings)
+	if err != nil {
+		return err
+	}
+	defer grpcClient.Clear()
+
+	reply, err := grpcClient.Client.Drain(grpcClient.CallContext, &pb.DrainRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Server \033[36m%s\033[0m is draining, %d active sessions\n", serverHostPort, reply.ActiveSessions)
+	return nil
 }
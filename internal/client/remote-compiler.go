@@ -1,8 +1,10 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
 	"github.com/AlexK0/popcorn/internal/common"
@@ -20,15 +22,19 @@ type RemoteCompiler struct {
 	sessionID      uint64
 
 	needCloseSession bool
+
+	// span is the parent of the remote compilation phases, nil if the tracing is disabled
+	span *common.Span
+	log  *common.Logger
 }
 
-func MakeRemoteCompiler(localCompiler *LocalCompiler, serverHostPort string) (*RemoteCompiler, error) {
+func MakeRemoteCompiler(ctx context.Context, localCompiler *LocalCompiler, serverHostPort string, settings *Settings) (*RemoteCompiler, error) {
 	clientUserName, clientID, err := common.MakeUniqueClientID()
 	if err != nil {
 		return nil, err
 	}
 
-	grpcClient, err := MakeGRPCClient(serverHostPort)
+	grpcClient, err := MakeGRPCClient(ctx, serverHostPort, settings)
 	if err != nil {
 		return nil, err
 	}
@@ -42,21 +48,32 @@ func MakeRemoteCompiler(localCompiler *LocalCompiler, serverHostPort string) (*R
 		grpcClient:     grpcClient,
 		clientID:       clientID,
 		clientUserName: clientUserName,
+
+		log: common.MakeLogger("",
+			common.LogField{Key: common.LogFieldSource, Value: localCompiler.inFile},
+			common.LogField{Key: common.LogFieldServer, Value: serverHostPort}),
 	}, nil
 }
 
-func (compiler *RemoteCompiler) transferFile(path string, index uint32, sha256Required bool, wg *common.WaitGroupWithError) {
+func (compiler *RemoteCompiler) transferFile(path string, index uint32, sha256Required bool, wg *common.WaitGroupWithError, parentSpan *common.Span) {
+	span := parentSpan.StartChild("TransferFile", common.SpanKindClient)
+	span.SetAttribute("file.path", path)
+	defer span.End()
+
 	var fileSHA256Message *pb.SHA256Message = nil
 	if sha256Required {
+		hashSpan := span.StartChild("HashFile", common.SpanKindInternal)
 		fileSHA256, err := common.GetFileSHA256(path)
+		hashSpan.End()
 		if err != nil {
+			span.SetError(err)
 			wg.Done(fmt.Errorf("Can't calculate SHA256 for file %q: %v", path, err))
 			return
 		}
 		fileSHA256Message = common.SHA256StructToSHA256Message(fileSHA256)
 	}
 
-	stream, err := compiler.grpcClient.Client.TransferFile(compiler.grpcClient.CallContext)
+	stream, err := compiler.grpcClient.Client.TransferFile(common.OutgoingContextWithSpan(compiler.grpcClient.CallContext, span))
 	if err != nil {
 		wg.Done(fmt.Errorf("Can't open grpc stream: %v", err))
 		return
@@ -83,6 +100,7 @@ func (compiler *RemoteCompiler) transferFile(path string, index uint32, sha256Re
 	}
 
 	if reply.Status == pb.RequiredStatus_FULL_COPY_REQUIRED {
+		span.SetAttribute("file.uploaded", true)
 		if err = common.TransferFileByChunks(path, func(chunk []byte) error {
 			return stream.Send(&pb.TransferFileRequest{Chunk: &pb.TransferFileRequest_FileBodyChunk{FileBodyChunk: chunk}})
 		}); err != nil {
@@ -109,9 +127,10 @@ func (compiler *RemoteCompiler) transferFile(path string, index uint32, sha256Re
 	}
 }
 
-func (compiler *RemoteCompiler) SetupEnvironment(files []*pb.FileMetadata, useObjCache bool) error {
+func (compiler *RemoteCompiler) SetupEnvironment(files []*pb.FileMetadata, objCacheMode pb.ObjectCacheMode, priority pb.CompilationPriority) error {
+	startSpan := compiler.span.StartChild("StartCompilationSession", common.SpanKindClient)
 	clientCacheStream, err := compiler.grpcClient.Client.StartCompilationSession(
-		compiler.grpcClient.CallContext,
+		common.OutgoingContextWithSpan(compiler.grpcClient.CallContext, startSpan),
 		&pb.StartCompilationSessionRequest{
 			ClientID:       compiler.clientID,
 			ClientUserName: compiler.clientUserName,
@@ -119,22 +138,31 @@ func (compiler *RemoteCompiler) SetupEnvironment(files []*pb.FileMetadata, useOb
 			Compiler:       compiler.name,
 			CompilerArgs:   compiler.remoteCmdArgs,
 			RequiredFiles:  files,
-			UseObjectCache: useObjCache,
+			// UseObjectCache is kept for older servers
+			UseObjectCache:  objCacheMode != pb.ObjectCacheMode_OBJ_CACHE_OFF,
+			ObjectCacheMode: objCacheMode,
+			Priority:        priority,
 		})
+	startSpan.SetError(err)
+	startSpan.End()
 	if err != nil {
 		return err
 	}
 
 	compiler.sessionID = clientCacheStream.SessionID
+	compiler.log = compiler.log.With(common.LogField{Key: common.LogFieldSessionID, Value: compiler.sessionID})
 	compiler.needCloseSession = true
 
+	uploadSpan := compiler.span.StartChild("UploadFiles", common.SpanKindInternal)
+	uploadSpan.SetAttribute("files.count", len(clientCacheStream.RequiredFiles))
+	defer uploadSpan.End()
 	sem := make(chan int, 6)
 	wg := common.WaitGroupWithError{}
 	wg.Add(len(clientCacheStream.RequiredFiles))
 	for _, requiredFile := range clientCacheStream.RequiredFiles {
 		sem <- 1
 		go func(index uint32, sendSHA256 bool) {
-			compiler.transferFile(files[index].FilePath, index, sendSHA256, &wg)
+			compiler.transferFile(files[index].FilePath, index, sendSHA256, &wg, uploadSpan)
 			<-sem
 		}(requiredFile.FileIndex, requiredFile.Status == pb.RequiredStatus_SHA256_REQUIRED)
 	}
@@ -142,8 +170,13 @@ func (compiler *RemoteCompiler) SetupEnvironment(files []*pb.FileMetadata, useOb
 }
 
 func (compiler *RemoteCompiler) CompileSource() (retCode int, stdout []byte, stderr []byte, err error) {
+	span := compiler.span.StartChild("CompileSource", common.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
 	res, err := compiler.grpcClient.Client.CompileSource(
-		compiler.grpcClient.CallContext,
+		common.OutgoingContextWithSpan(compiler.grpcClient.CallContext, span),
 		&pb.CompileSourceRequest{
 			SessionID:              compiler.sessionID,
 			CloseSessionAfterBuild: true,
@@ -197,14 +230,29 @@ func (compiler *RemoteCompiler) CompileSource() (retCode int, stdout []byte, std
 	if epilogue == nil {
 		return 0, nil, nil, fmt.Errorf("Epilogue for %q is missed", compiler.outFile)
 	}
+	span.SetAttribute("obj_cache.result", epilogue.ObjectCacheResult.String())
+	span.SetAttribute("queue_wait_ns", epilogue.QueueWaitTime)
+	span.SetAttribute("compile_ns", epilogue.CompileTime)
+	span.SetAttribute("server.name", epilogue.ServerName)
+	compiler.log.Info(fmt.Sprintf("Compiled obj %q on %s (%s): obj cache %s, queue wait %s, compile %s, files uploaded %d, from src cache %d, system headers %d",
+		compiler.outFile, epilogue.ServerName, epilogue.ServerVersion, epilogue.ObjectCacheResult,
+		time.Duration(epilogue.QueueWaitTime), time.Duration(epilogue.CompileTime),
+		epilogue.UploadedFiles, epilogue.FilesFromSrcCache, epilogue.SystemHeaders))
 
 	return int(epilogue.CompilerRetCode), epilogue.CompilerStderr, epilogue.CompilerStdout, nil
 }
 
 func (compiler *RemoteCompiler) Clear() {
 	if compiler.needCloseSession {
+		ctx := compiler.grpcClient.CallContext
+		if ctx.Err() != nil {
+			// The call context is cancelled if the client is interrupted, the session is closed anyway
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+		}
 		_, _ = compiler.grpcClient.Client.CloseSession(
-			compiler.grpcClient.CallContext,
+			ctx,
 			&pb.CloseSessionRequest{
 				SessionID: compiler.sessionID,
 			})
@@ -1,18 +1,79 @@
 package client
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
 
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
 	"github.com/AlexK0/popcorn/internal/common"
 )
 
 // Settings ...
 type Settings struct {
-	Servers     []string
-	LogFileName string
-	LogSeverity string
-	UseObjCache bool
+	Servers      []string
+	LogFileName  string
+	LogSeverity  string
+	LogFormat    string
+	LogSink      string
+	LogLevels    string
+	ObjCacheMode pb.ObjectCacheMode
+
+	LocalJobsLimit   int
+	LocalJobsLockDir string
+
+	UseTLS        bool
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+
+	AuthToken string
+
+	Priority pb.CompilationPriority
+
+	TraceDir    string
+	TraceURL    string
+	TraceParent string
+
+	// warnings are logged after the logger is initialized
+	warnings []string
+}
+
+// LogWarnings logs the problems found in the environment variables.
+func (settings *Settings) LogWarnings() {
+	for _, warning := range settings.warnings {
+		common.LogWarning(warning)
+	}
+}
+
+func parseBoolValue(value string) bool {
+	return (value == "1") ||
+		strings.EqualFold(value, "yes") || strings.EqualFold(value, "true") ||
+		strings.EqualFold(value, "on") || strings.EqualFold(value, "enable")
+}
+
+func parseObjCacheMode(value string) (pb.ObjectCacheMode, error) {
+	switch strings.ToLower(value) {
+	case "read-write", "rw":
+		return pb.ObjectCacheMode_OBJ_CACHE_READ_WRITE, nil
+	case "read-only", "ro":
+		return pb.ObjectCacheMode_OBJ_CACHE_READ_ONLY, nil
+	case "write-only", "wo":
+		return pb.ObjectCacheMode_OBJ_CACHE_WRITE_ONLY, nil
+	case "recache":
+		return pb.ObjectCacheMode_OBJ_CACHE_RECACHE, nil
+	case "0", "no", "false", "off", "disable":
+		return pb.ObjectCacheMode_OBJ_CACHE_OFF, nil
+	}
+	if parseBoolValue(value) {
+		return pb.ObjectCacheMode_OBJ_CACHE_READ_WRITE, nil
+	}
+	return pb.ObjectCacheMode_OBJ_CACHE_OFF, fmt.Errorf("Unknown POPCORN_OBJ_CACHE value %q, the object cache is disabled; "+
+		"accepted values are read-write (rw), read-only (ro), write-only (wo), recache, on and off", value)
 }
 
 func getEnvValue(envVar string, key string) string {
@@ -25,7 +86,10 @@ func getEnvValue(envVar string, key string) string {
 // ReadClientSettings ...
 func ReadClientSettings() *Settings {
 	settings := Settings{
-		LogSeverity: common.WarningSeverity,
+		LogSeverity:      common.WarningSeverity,
+		ObjCacheMode:     pb.ObjectCacheMode_OBJ_CACHE_OFF,
+		LocalJobsLimit:   runtime.NumCPU(),
+		LocalJobsLockDir: "/tmp/popcorn-local-jobs",
 	}
 	for _, envVar := range os.Environ() {
 		if value := getEnvValue(envVar, "POPCORN_SERVERS="); len(value) != 0 {
@@ -41,12 +105,58 @@ func ReadClientSettings() *Settings {
 			settings.LogFileName = value
 		} else if value := getEnvValue(envVar, "POPCORN_LOG_SEVERITY="); len(value) != 0 {
 			settings.LogSeverity = value
+		} else if value := getEnvValue(envVar, "POPCORN_LOG_FORMAT="); len(value) != 0 {
+			settings.LogFormat = value
+		} else if value := getEnvValue(envVar, "POPCORN_LOG_SINK="); len(value) != 0 {
+			settings.LogSink = value
+		} else if value := getEnvValue(envVar, "POPCORN_LOG_LEVELS="); len(value) != 0 {
+			settings.LogLevels = value
 		} else if value := getEnvValue(envVar, "POPCORN_OBJ_CACHE="); len(value) != 0 {
-			settings.UseObjCache = (value == "1") ||
-				strings.EqualFold(value, "yes") || strings.EqualFold(value, "true") ||
-				strings.EqualFold(value, "on") || strings.EqualFold(value, "enable")
+			objCacheMode, err := parseObjCacheMode(value)
+			if err != nil {
+				settings.warnings = append(settings.warnings, err.Error())
+			}
+			settings.ObjCacheMode = objCacheMode
+		} else if value := getEnvValue(envVar, "POPCORN_LOCAL_JOBS="); len(value) != 0 {
+			if jobs, err := strconv.Atoi(value); err == nil {
+				settings.LocalJobsLimit = jobs
+			}
+		} else if value := getEnvValue(envVar, "POPCORN_LOCAL_JOBS_LOCK_DIR="); len(value) != 0 {
+			settings.LocalJobsLockDir = value
+		} else if value := getEnvValue(envVar, "POPCORN_TLS="); len(value) != 0 {
+			settings.UseTLS = parseBoolValue(value)
+		} else if value := getEnvValue(envVar, "POPCORN_TLS_CA_FILE="); len(value) != 0 {
+			settings.TLSCAFile = value
+		} else if value := getEnvValue(envVar, "POPCORN_TLS_CERT_FILE="); len(value) != 0 {
+			settings.TLSCertFile = value
+		} else if value := getEnvValue(envVar, "POPCORN_TLS_KEY_FILE="); len(value) != 0 {
+			settings.TLSKeyFile = value
+		} else if value := getEnvValue(envVar, "POPCORN_TLS_SERVER_NAME="); len(value) != 0 {
+			settings.TLSServerName = value
+		} else if value := getEnvValue(envVar, "POPCORN_PRIORITY="); len(value) != 0 {
+			if strings.EqualFold(value, "interactive") {
+				settings.Priority = pb.CompilationPriority_PRIORITY_INTERACTIVE
+			} else if strings.EqualFold(value, "batch") || strings.EqualFold(value, "ci") {
+				settings.Priority = pb.CompilationPriority_PRIORITY_BATCH
+			}
+		} else if value := getEnvValue(envVar, "POPCORN_TRACE_DIR="); len(value) != 0 {
+			settings.TraceDir = value
+		} else if value := getEnvValue(envVar, "POPCORN_TRACE_URL="); len(value) != 0 {
+			settings.TraceURL = value
+		} else if value := getEnvValue(envVar, "POPCORN_TRACE_PARENT="); len(value) != 0 {
+			settings.TraceParent = value
+		} else if value := getEnvValue(envVar, "POPCORN_AUTH_TOKEN="); len(value) != 0 {
+			settings.AuthToken = value
+		} else if value := getEnvValue(envVar, "POPCORN_AUTH_TOKEN_FILE="); len(value) != 0 && len(settings.AuthToken) == 0 {
+			if token, err := ioutil.ReadFile(value); err == nil {
+				settings.AuthToken = strings.TrimSpace(string(token))
+			} else {
+				common.LogWarning("Can't read auth token file:", err)
+			}
 		}
 	}
 
+	settings.UseTLS = settings.UseTLS || len(settings.TLSCAFile) != 0 || len(settings.TLSCertFile) != 0
+
 	return &settings
 }
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Cgroup is a cgroup v2 directory with memory and cpu limits.
+type Cgroup struct {
+	Dir string
+}
+
+// IsCgroupV2Available checks that the directory is a cgroup v2 directory and enables memory and cpu controllers for its children.
+func IsCgroupV2Available(parentDir string) bool {
+	if _, err := os.Stat(path.Join(parentDir, "cgroup.controllers")); err != nil {
+		return false
+	}
+	_ = ioutil.WriteFile(path.Join(parentDir, "cgroup.subtree_control"), []byte("+memory +cpu"), 0)
+	return true
+}
+
+// MakeCgroup creates a child cgroup, zero limits are not applied.
+func MakeCgroup(parentDir string, name string, memoryLimit int64, cpuLimit float64) (*Cgroup, error) {
+	cgroup := &Cgroup{Dir: path.Join(parentDir, name)}
+	if err := os.Mkdir(cgroup.Dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	if memoryLimit > 0 {
+		if err := cgroup.write("memory.max", strconv.FormatInt(memoryLimit, 10)); err != nil {
+			cgroup.Remove()
+			return nil, err
+		}
+		_ = cgroup.write("memory.swap.max", "0")
+	}
+	if cpuLimit > 0 {
+		const period = 100000
+		if err := cgroup.write("cpu.max", fmt.Sprintf("%d %d", int64(cpuLimit*period), period)); err != nil {
+			cgroup.Remove()
+			return nil, err
+		}
+	}
+	return cgroup, nil
+}
+
+func (cgroup *Cgroup) write(file string, value string) error {
+	return ioutil.WriteFile(path.Join(cgroup.Dir, file), []byte(value), 0)
+}
+
+// OOMKills returns the amount of processes killed by the memory limit.
+func (cgroup *Cgroup) OOMKills() int64 {
+	file, err := os.Open(path.Join(cgroup.Dir, "memory.events"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			kills, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kills
+		}
+	}
+	return 0
+}
+
+// Remove ...
+func (cgroup *Cgroup) Remove() {
+	if err := os.Remove(cgroup.Dir); err != nil {
+		LogWarning("Can't remove cgroup:", err)
+	}
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+type bearerTokenCredentials struct {
+	token string
+}
+
+// MakeBearerTokenCredentials makes per RPC credentials with the token in the authorization header.
+// The token is never sent over plaintext connections.
+func MakeBearerTokenCredentials(token string) credentials.PerRPCCredentials {
+	return &bearerTokenCredentials{token: token}
+}
+
+func (c *bearerTokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c *bearerTokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// grpcLoggerVerbosity is the verbosity of grpc info logs
+const grpcLoggerVerbosity = 2
+
+// grpcLogger passes grpc logs to the logger, the grpc component like "[core]" becomes the record component.
+type grpcLogger struct{}
+
+func writeGRPCLogRecord(severity logSeverity, args ...interface{}) {
+	component := "grpc"
+	if len(args) != 0 {
+		if prefix, ok := args[0].(string); ok && strings.HasPrefix(prefix, "[") && strings.HasSuffix(prefix, "]") {
+			component = prefix[1 : len(prefix)-1]
+			args = args[1:]
+		}
+	}
+	writeLogRecord(component, severity, nil, args...)
+}
+
+func (grpcLogger) Info(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityInfo, fmt.Sprint(args...))
+}
+
+func (grpcLogger) Infoln(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityInfo, args...)
+}
+
+func (grpcLogger) Infof(format string, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityInfo, fmt.Sprintf(format, args...))
+}
+
+func (grpcLogger) InfoDepth(depth int, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityInfo, args...)
+}
+
+func (grpcLogger) Warning(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityWarning, fmt.Sprint(args...))
+}
+
+func (grpcLogger) Warningln(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityWarning, args...)
+}
+
+func (grpcLogger) Warningf(format string, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityWarning, fmt.Sprintf(format, args...))
+}
+
+func (grpcLogger) WarningDepth(depth int, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityWarning, args...)
+}
+
+func (grpcLogger) Error(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityError, fmt.Sprint(args...))
+}
+
+func (grpcLogger) Errorln(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityError, args...)
+}
+
+func (grpcLogger) Errorf(format string, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityError, fmt.Sprintf(format, args...))
+}
+
+func (grpcLogger) ErrorDepth(depth int, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityError, args...)
+}
+
+func (grpcLogger) Fatal(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityFatal, fmt.Sprint(args...))
+}
+
+func (grpcLogger) Fatalln(args ...interface{}) {
+	writeGRPCLogRecord(logSeverityFatal, args...)
+}
+
+func (grpcLogger) Fatalf(format string, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityFatal, fmt.Sprintf(format, args...))
+}
+
+func (grpcLogger) FatalDepth(depth int, args ...interface{}) {
+	writeGRPCLogRecord(logSeverityFatal, args...)
+}
+
+func (grpcLogger) V(l int) bool {
+	return l <= grpcLoggerVerbosity
+}
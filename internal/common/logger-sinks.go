@@ -0,0 +1,227 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func formatLogFieldValue(value interface{}) string {
+	text := fmt.Sprint(value)
+	if strings.ContainsAny(text, " =\"\n") {
+		return strconv.Quote(text)
+	}
+	return text
+}
+
+// formatTextLogRecord formats the record like "[component] message key=value", without the time and the severity.
+func formatTextLogRecord(buffer *bytes.Buffer, record *logRecord) {
+	buffer.WriteString("[")
+	buffer.WriteString(record.component)
+	buffer.WriteString("] ")
+	buffer.WriteString(record.message)
+	for _, field := range record.fields {
+		buffer.WriteString(" ")
+		buffer.WriteString(field.Key)
+		buffer.WriteString("=")
+		buffer.WriteString(formatLogFieldValue(field.Value))
+	}
+}
+
+func writeJSONLogField(buffer *bytes.Buffer, key string, value interface{}) {
+	keyData, _ := json.Marshal(key)
+	valueData, err := json.Marshal(value)
+	if err != nil {
+		valueData, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buffer.WriteString(",")
+	buffer.Write(keyData)
+	buffer.WriteString(":")
+	buffer.Write(valueData)
+}
+
+// formatJSONLogRecord formats the record as one line json object, the contextual fields follow the standard ones.
+func formatJSONLogRecord(buffer *bytes.Buffer, record *logRecord) {
+	buffer.WriteString(`{"time":"`)
+	buffer.WriteString(record.time.Format(time.RFC3339Nano))
+	buffer.WriteString(`"`)
+	writeJSONLogField(buffer, "level", record.severity.String())
+	writeJSONLogField(buffer, "component", record.component)
+	writeJSONLogField(buffer, "msg", record.message)
+	for _, field := range record.fields {
+		if err, ok := field.Value.(error); ok {
+			field.Value = err.Error()
+		}
+		writeJSONLogField(buffer, field.Key, field.Value)
+	}
+	buffer.WriteString("}")
+}
+
+// fileLogSink writes to the file, or to stdout if the file name is empty.
+type fileLogSink struct {
+	fileName string
+	format   string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (sink *fileLogSink) write(record *logRecord) {
+	var buffer bytes.Buffer
+	if sink.format == LogFormatJSON {
+		formatJSONLogRecord(&buffer, record)
+	} else {
+		buffer.WriteString(record.severity.String())
+		buffer.WriteString(": ")
+		buffer.WriteString(record.time.Format("2006/01/02 15:04:05"))
+		buffer.WriteString(" ")
+		formatTextLogRecord(&buffer, record)
+	}
+	buffer.WriteString("\n")
+
+	sink.mu.Lock()
+	_, _ = sink.file.Write(buffer.Bytes())
+	sink.mu.Unlock()
+}
+
+func (sink *fileLogSink) reopen() error {
+	newFile := os.Stdout
+	if len(sink.fileName) != 0 {
+		var err error
+		newFile, err = os.OpenFile(sink.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+		if err != nil {
+			return err
+		}
+	} else if sink.file != nil {
+		return nil
+	}
+
+	sink.mu.Lock()
+	oldFile := sink.file
+	sink.file = newFile
+	sink.mu.Unlock()
+	if oldFile != nil && len(sink.fileName) != 0 {
+		return oldFile.Close()
+	}
+	return nil
+}
+
+func (sink *fileLogSink) close() error {
+	if len(sink.fileName) == 0 {
+		return nil
+	}
+	return sink.file.Close()
+}
+
+// syslogSink writes to the local syslog, the syslog adds the time and the severity itself.
+type syslogSink struct {
+	writer *syslog.Writer
+	format string
+}
+
+func makeSyslogSink(format string) (*syslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, filepath.Base(os.Args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Can't connect to syslog: %v", err)
+	}
+	return &syslogSink{writer: writer, format: format}, nil
+}
+
+func (sink *syslogSink) write(record *logRecord) {
+	var buffer bytes.Buffer
+	if sink.format == LogFormatJSON {
+		formatJSONLogRecord(&buffer, record)
+	} else {
+		formatTextLogRecord(&buffer, record)
+	}
+	message := buffer.String()
+	switch record.severity {
+	case logSeverityInfo:
+		_ = sink.writer.Info(message)
+	case logSeverityWarning:
+		_ = sink.writer.Warning(message)
+	case logSeverityError:
+		_ = sink.writer.Err(message)
+	default:
+		_ = sink.writer.Crit(message)
+	}
+}
+
+func (sink *syslogSink) reopen() error {
+	return nil
+}
+
+func (sink *syslogSink) close() error {
+	return sink.writer.Close()
+}
+
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldSink sends records by the journald native protocol, the contextual fields become journal fields
+// with the POPCORN_ prefix, e.g. POPCORN_SESSION_ID.
+type journaldSink struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+func makeJournaldSink() (*journaldSink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("Can't connect to journald: %v", err)
+	}
+	return &journaldSink{conn: conn, identifier: filepath.Base(os.Args[0])}, nil
+}
+
+func journaldFieldName(key string) string {
+	name := []byte("POPCORN_" + strings.ToUpper(key))
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// writeJournaldField uses the binary format for multiline values.
+func writeJournaldField(buffer *bytes.Buffer, name string, value string) {
+	buffer.WriteString(name)
+	if strings.ContainsRune(value, '\n') {
+		buffer.WriteString("\n")
+		_ = binary.Write(buffer, binary.LittleEndian, uint64(len(value)))
+	} else {
+		buffer.WriteString("=")
+	}
+	buffer.WriteString(value)
+	buffer.WriteString("\n")
+}
+
+var journaldPriorities = [...]string{"6", "4", "3", "2"}
+
+func (sink *journaldSink) write(record *logRecord) {
+	var buffer bytes.Buffer
+	writeJournaldField(&buffer, "MESSAGE", record.message)
+	writeJournaldField(&buffer, "PRIORITY", journaldPriorities[record.severity])
+	writeJournaldField(&buffer, "SYSLOG_IDENTIFIER", sink.identifier)
+	writeJournaldField(&buffer, "POPCORN_COMPONENT", record.component)
+	for _, field := range record.fields {
+		writeJournaldField(&buffer, journaldFieldName(field.Key), fmt.Sprint(field.Value))
+	}
+	_, _ = sink.conn.Write(buffer.Bytes())
+}
+
+func (sink *journaldSink) reopen() error {
+	return nil
+}
+
+func (sink *journaldSink) close() error {
+	return sink.conn.Close()
+}
@@ -2,24 +2,16 @@ package common
 
 import (
 	"errors"
-	"io/ioutil"
+	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc/grpclog"
 )
 
-type fileLogWriter struct {
-	logFile string
-	mu      sync.RWMutex
-	file    *os.File
-
-	logComponent grpclog.DepthLoggerV2
-}
-
 var (
-	logWriter = fileLogWriter{logComponent: grpclog.Component("unknown")}
-
 	// ErrUnknownSeverity ...
 	ErrUnknownSeverity = errors.New("Unknown logger severity")
 
@@ -31,85 +23,306 @@ var (
 	ErrorSeverity = "ERROR"
 )
 
-func init() {
-	_ = LoggerInit("unknown", "", InfoSeverity)
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Log sinks
+const (
+	LogSinkFile     = "file"
+	LogSinkStderr   = "stderr"
+	LogSinkSyslog   = "syslog"
+	LogSinkJournald = "journald"
+)
+
+type logSeverity int
+
+const (
+	logSeverityInfo logSeverity = iota
+	logSeverityWarning
+	logSeverityError
+	logSeverityFatal
+)
+
+var logSeverityNames = [...]string{"INFO", "WARNING", "ERROR", "FATAL"}
+
+func (severity logSeverity) String() string {
+	return logSeverityNames[severity]
+}
+
+func parseLogSeverity(severity string) (logSeverity, error) {
+	switch strings.ToUpper(severity) {
+	case InfoSeverity:
+		return logSeverityInfo, nil
+	case WarningSeverity:
+		return logSeverityWarning, nil
+	case ErrorSeverity:
+		return logSeverityError, nil
+	}
+	return 0, ErrUnknownSeverity
+}
+
+// LoggerSettings ...
+type LoggerSettings struct {
+	// FileName is used by the file sink, the log goes to stdout if it is empty
+	FileName string
+	Severity string
+	// Format is text or json
+	Format string
+	// Sink is file, stderr, syslog or journald
+	Sink string
+	// ComponentSeverities overrides the severity of components, e.g. "core=ERROR,cache=INFO"
+	ComponentSeverities string
+}
+
+// LogField is a contextual field of log records, e.g. the session id.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// Keys of the contextual fields which are used by both the server and the client
+const (
+	LogFieldSessionID  = "session_id"
+	LogFieldClientUser = "client_user"
+	LogFieldSource     = "source"
+	LogFieldServer     = "server"
+	LogFieldPhase      = "phase"
+)
+
+type logRecord struct {
+	time      time.Time
+	severity  logSeverity
+	component string
+	message   string
+	fields    []LogField
+}
+
+// logSink writes records, the sinks are safe for concurrent use.
+type logSink interface {
+	write(record *logRecord)
+	// reopen is called on the log rotation
+	reopen() error
+	close() error
+}
+
+type loggerState struct {
+	mu sync.RWMutex
+
+	sink                logSink
+	component           string
+	severity            logSeverity
+	componentSeverities map[string]logSeverity
+	fields              []LogField
+	fileName            string
 }
 
-func (writer *fileLogWriter) Write(p []byte) (int, error) {
-	writer.mu.RLock()
-	n, err := writer.file.Write(p)
-	writer.mu.RUnlock()
-	return n, err
+var logger = loggerState{
+	sink:      &fileLogSink{file: os.Stdout, format: LogFormatText},
+	component: "unknown",
+}
+
+func init() {
+	grpclog.SetLoggerV2(grpcLogger{})
 }
 
-func (writer *fileLogWriter) reopenLog(newLogFile string) error {
-	var err error = nil
-	new_file := os.Stdout
-	if len(newLogFile) != 0 {
-		new_file, err = os.OpenFile(newLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+func parseComponentSeverities(componentSeverities string) (map[string]logSeverity, error) {
+	severities := make(map[string]logSeverity)
+	for _, item := range strings.Split(componentSeverities, ",") {
+		if item = strings.TrimSpace(item); len(item) == 0 {
+			continue
+		}
+		keyValue := strings.SplitN(item, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("Bad component severity %q, <component>=<severity> is expected", item)
+		}
+		severity, err := parseLogSeverity(keyValue[1])
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("Bad component severity %q: %v", item, err)
 		}
+		severities[strings.TrimSpace(keyValue[0])] = severity
+	}
+	return severities, nil
+}
+
+func makeLogSink(settings *LoggerSettings) (logSink, error) {
+	format := settings.Format
+	if len(format) == 0 {
+		format = LogFormatText
+	}
+	if format != LogFormatText && format != LogFormatJSON {
+		return nil, fmt.Errorf("Unknown log format %q, text or json is expected", format)
 	}
 
-	writer.mu.Lock()
-	if len(writer.logFile) != 0 {
-		err = writer.file.Close()
+	switch settings.Sink {
+	case "", LogSinkFile:
+		sink := &fileLogSink{fileName: settings.FileName, format: format}
+		return sink, sink.reopen()
+	case LogSinkStderr:
+		return &fileLogSink{file: os.Stderr, format: format}, nil
+	case LogSinkSyslog:
+		return makeSyslogSink(format)
+	case LogSinkJournald:
+		return makeJournaldSink()
 	}
-	writer.logFile = newLogFile
-	writer.file = new_file
-	writer.mu.Unlock()
-	return err
+	return nil, fmt.Errorf("Unknown log sink %q, file, stderr, syslog or journald is expected", settings.Sink)
 }
 
 // LoggerInit ...
-func LoggerInit(component string, logFile string, severity string) error {
-	err := logWriter.reopenLog(logFile)
+func LoggerInit(component string, settings LoggerSettings) error {
+	severity, err := parseLogSeverity(settings.Severity)
+	if err != nil {
+		return err
+	}
+	componentSeverities, err := parseComponentSeverities(settings.ComponentSeverities)
+	if err != nil {
+		return err
+	}
+	sink, err := makeLogSink(&settings)
 	if err != nil {
 		return err
 	}
 
-	switch severity {
-	case InfoSeverity:
-		grpclog.SetLoggerV2(grpclog.NewLoggerV2WithVerbosity(&logWriter, ioutil.Discard, ioutil.Discard, 2))
-	case WarningSeverity:
-		grpclog.SetLoggerV2(grpclog.NewLoggerV2WithVerbosity(ioutil.Discard, &logWriter, ioutil.Discard, 2))
-	case ErrorSeverity:
-		grpclog.SetLoggerV2(grpclog.NewLoggerV2WithVerbosity(ioutil.Discard, ioutil.Discard, &logWriter, 2))
-	default:
-		return ErrUnknownSeverity
+	logger.mu.Lock()
+	oldSink := logger.sink
+	logger.sink = sink
+	logger.component = component
+	logger.severity = severity
+	logger.componentSeverities = componentSeverities
+	logger.fileName = ""
+	if len(settings.Sink) == 0 || settings.Sink == LogSinkFile {
+		logger.fileName = settings.FileName
 	}
+	logger.mu.Unlock()
+	return oldSink.close()
+}
 
-	logWriter.logComponent = grpclog.Component(component)
-	return nil
+// SetLogFields sets the fields which are added to all records, e.g. the server name.
+func SetLogFields(fields ...LogField) {
+	logger.mu.Lock()
+	logger.fields = fields
+	logger.mu.Unlock()
 }
 
-// RotateLogFile
+// RotateLogFile reopens the log file, it does nothing for the other sinks.
 func RotateLogFile() error {
-	return logWriter.reopenLog(logWriter.logFile)
+	logger.mu.RLock()
+	sink := logger.sink
+	logger.mu.RUnlock()
+	return sink.reopen()
 }
 
 // GetLogFileName ...
 func GetLogFileName() string {
-	return logWriter.logFile
+	logger.mu.RLock()
+	defer logger.mu.RUnlock()
+	return logger.fileName
+}
+
+func writeLogRecord(component string, severity logSeverity, fields []LogField, v ...interface{}) {
+	logger.mu.RLock()
+	if len(component) == 0 {
+		component = logger.component
+	}
+	minSeverity, ok := logger.componentSeverities[component]
+	if !ok {
+		minSeverity = logger.severity
+	}
+	if severity < minSeverity {
+		logger.mu.RUnlock()
+		return
+	}
+	sink := logger.sink
+	if len(logger.fields) != 0 {
+		fields = append(append(make([]LogField, 0, len(logger.fields)+len(fields)), logger.fields...), fields...)
+	}
+	logger.mu.RUnlock()
+
+	sink.write(&logRecord{
+		time:      time.Now(),
+		severity:  severity,
+		component: component,
+		message:   strings.TrimSuffix(fmt.Sprintln(v...), "\n"),
+		fields:    fields,
+	})
+	if severity == logSeverityFatal {
+		os.Exit(1)
+	}
+}
+
+// Logger writes records of the component with contextual fields, the nil logger writes to the main component.
+type Logger struct {
+	component string
+	fields    []LogField
+}
+
+// MakeLogger returns the logger of the component, the main component is used if it is empty.
+func MakeLogger(component string, fields ...LogField) *Logger {
+	return &Logger{component: component, fields: fields}
+}
+
+// With returns the logger with additional fields.
+func (l *Logger) With(fields ...LogField) *Logger {
+	if l == nil {
+		return MakeLogger("", fields...)
+	}
+	return &Logger{
+		component: l.component,
+		fields:    append(append(make([]LogField, 0, len(l.fields)+len(fields)), l.fields...), fields...),
+	}
+}
+
+// WithPhase returns the logger with the phase field, e.g. "transfer" or "compile".
+func (l *Logger) WithPhase(phase string) *Logger {
+	return l.With(LogField{Key: LogFieldPhase, Value: phase})
+}
+
+func (l *Logger) write(severity logSeverity, v ...interface{}) {
+	if l == nil {
+		writeLogRecord("", severity, nil, v...)
+	} else {
+		writeLogRecord(l.component, severity, l.fields, v...)
+	}
+}
+
+// Info ...
+func (l *Logger) Info(v ...interface{}) {
+	l.write(logSeverityInfo, v...)
+}
+
+// Warning ...
+func (l *Logger) Warning(v ...interface{}) {
+	l.write(logSeverityWarning, v...)
+}
+
+// Error ...
+func (l *Logger) Error(v ...interface{}) {
+	l.write(logSeverityError, v...)
+}
+
+// Fatal ...
+func (l *Logger) Fatal(v ...interface{}) {
+	l.write(logSeverityFatal, v...)
 }
 
 // LogInfo ...
 func LogInfo(v ...interface{}) {
-	logWriter.logComponent.Info(v...)
+	writeLogRecord("", logSeverityInfo, nil, v...)
 }
 
 // LogWarning ...
 func LogWarning(v ...interface{}) {
-	logWriter.logComponent.Warning(v...)
+	writeLogRecord("", logSeverityWarning, nil, v...)
 }
 
 // LogError ...
 func LogError(v ...interface{}) {
-	logWriter.logComponent.Error(v...)
+	writeLogRecord("", logSeverityError, nil, v...)
 }
 
 // LogFatal ...
 func LogFatal(v ...interface{}) {
-	logWriter.logComponent.Fatal(v...)
+	writeLogRecord("", logSeverityFatal, nil, v...)
 }
@@ -0,0 +1,334 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	sandboxInitName = "popcorn-sandbox-init"
+
+	prSetNoNewPrivs       = 38
+	prCapAmbient          = 47
+	prCapAmbientClearAll  = 4
+	capSysAdmin           = 21
+	preservedMountFlags   = syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC | syscall.MS_NOATIME | syscall.MS_NODIRATIME | syscall.MS_RELATIME
+	sandboxOldRootDirName = ".old-root"
+)
+
+// Sandbox runs processes in new user, mount, pid and network namespaces.
+// The root of the sandbox contains read only binds of ReadOnlyDirs, the writable working dir and a private /tmp.
+// Inside the sandbox the process is running as UID:GID without any capabilities.
+type Sandbox struct {
+	// RootDir is an empty directory for mounting the sandbox root, it stays empty outside of the sandbox.
+	RootDir      string
+	ReadOnlyDirs []string
+	UID          int
+	GID          int
+}
+
+// MakeSandbox ...
+func MakeSandbox(rootDir string, readOnlyDirs []string, uid int, gid int) (*Sandbox, error) {
+	if err := os.MkdirAll(rootDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &Sandbox{
+		RootDir:      rootDir,
+		ReadOnlyDirs: readOnlyDirs,
+		UID:          uid,
+		GID:          gid,
+	}, nil
+}
+
+// ProcessLimits are applied to the process right before launching it.
+type ProcessLimits struct {
+	CPUTime      time.Duration
+	AddressSpace int64
+	// CgroupDir is a cgroup v2 directory, which the process joins.
+	CgroupDir string
+	Nice      int
+}
+
+func (limits *ProcessLimits) isEmpty() bool {
+	return limits == nil || (limits.CPUTime <= 0 && limits.AddressSpace <= 0 && len(limits.CgroupDir) == 0 && limits.Nice == 0)
+}
+
+type sandboxInitConfig struct {
+	UseNamespaces bool
+	RootDir       string
+	WorkingDir    string
+	ReadOnlyDirs  []string
+
+	CPUTimeSeconds uint64
+	AddressSpace   uint64
+	CgroupDir      string
+	Nice           int
+}
+
+// MakeCommand prepares a command, which runs name with args in workingDir inside the sandbox with the limits.
+// The sandbox can be nil, in this case the command only applies the limits.
+// The current executable must call HandleSandboxInit at the start of main.
+// The command is launched in a new process group, use KillProcessGroup for stopping it.
+func MakeCommand(sandbox *Sandbox, limits *ProcessLimits, workingDir string, name string, args ...string) *exec.Cmd {
+	if sandbox == nil && limits.isEmpty() {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = workingDir
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		return cmd
+	}
+
+	config := sandboxInitConfig{WorkingDir: workingDir}
+	if limits != nil {
+		if limits.CPUTime > 0 {
+			config.CPUTimeSeconds = uint64((limits.CPUTime + time.Second - 1) / time.Second)
+		}
+		if limits.AddressSpace > 0 {
+			config.AddressSpace = uint64(limits.AddressSpace)
+		}
+		config.CgroupDir = limits.CgroupDir
+		config.Nice = limits.Nice
+	}
+
+	sysProcAttr := &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	if sandbox != nil {
+		config.UseNamespaces = true
+		config.RootDir = sandbox.RootDir
+		config.ReadOnlyDirs = sandbox.ReadOnlyDirs
+
+		sysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS
+		sysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: sandbox.UID, HostID: os.Geteuid(), Size: 1}}
+		sysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: sandbox.GID, HostID: os.Getegid(), Size: 1}}
+		sysProcAttr.GidMappingsEnableSetgroups = false
+		// Required for the mounts, dropped before launching the process
+		sysProcAttr.AmbientCaps = []uintptr{capSysAdmin}
+	}
+
+	encodedConfig, _ := json.Marshal(&config)
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "/proc/self/exe"
+	}
+	cmd := exec.Command(executable)
+	cmd.Args = append([]string{sandboxInitName, string(encodedConfig), name}, args...)
+	cmd.Dir = workingDir
+	cmd.SysProcAttr = sysProcAttr
+	return cmd
+}
+
+// KillProcessGroup kills the process started by the command from MakeCommand with all its children.
+func KillProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+func mountReadOnlyBind(source string, target string) error {
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("Can't bind %q: %v", source, err)
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(target, &stat); err != nil {
+		return err
+	}
+	// Locked flags of the source mount must be preserved on remount inside user namespace
+	flags := uintptr(syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY) | (uintptr(stat.Flags) & preservedMountFlags)
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("Can't remount %q as read only: %v", target, err)
+	}
+	return nil
+}
+
+func bindReadOnlyPath(rootDir string, hostPath string) error {
+	info, err := os.Lstat(hostPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sandboxPath := path.Join(rootDir, hostPath)
+	if err := os.MkdirAll(path.Dir(sandboxPath), os.ModePerm); err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(hostPath)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, sandboxPath)
+	}
+	if info.IsDir() {
+		err = os.Mkdir(sandboxPath, os.ModePerm)
+	} else {
+		err = WriteFile(sandboxPath, nil)
+	}
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+	return mountReadOnlyBind(hostPath, sandboxPath)
+}
+
+func setupSandbox(rootDir string, workingDir string, readOnlyDirs []string) error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("Can't make mounts private: %v", err)
+	}
+	if err := syscall.Mount("tmpfs", rootDir, "tmpfs", syscall.MS_NOSUID, "mode=0755,size=16m"); err != nil {
+		return fmt.Errorf("Can't mount sandbox root: %v", err)
+	}
+
+	for _, dir := range readOnlyDirs {
+		if err := bindReadOnlyPath(rootDir, dir); err != nil {
+			return err
+		}
+	}
+	for _, device := range []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom"} {
+		sandboxDevice := path.Join(rootDir, device)
+		if err := os.MkdirAll(path.Dir(sandboxDevice), os.ModePerm); err != nil {
+			return err
+		}
+		if err := WriteFile(sandboxDevice, nil); err != nil {
+			return err
+		}
+		if err := syscall.Mount(device, sandboxDevice, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("Can't bind %q: %v", device, err)
+		}
+	}
+
+	sandboxTmp := path.Join(rootDir, "tmp")
+	if err := os.MkdirAll(sandboxTmp, os.ModePerm); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", sandboxTmp, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777"); err != nil {
+		return fmt.Errorf("Can't mount /tmp: %v", err)
+	}
+
+	sandboxProc := path.Join(rootDir, "proc")
+	if err := os.MkdirAll(sandboxProc, os.ModePerm); err != nil {
+		return err
+	}
+	// Not critical, but some compilers look for themselves via /proc/self/exe
+	_ = syscall.Mount("proc", sandboxProc, "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+
+	sandboxWorkingDir := path.Join(rootDir, workingDir)
+	if err := os.MkdirAll(sandboxWorkingDir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := syscall.Mount(workingDir, sandboxWorkingDir, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("Can't bind working dir: %v", err)
+	}
+
+	oldRoot := path.Join(rootDir, sandboxOldRootDirName)
+	if err := os.Mkdir(oldRoot, os.ModePerm); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(rootDir, oldRoot); err != nil {
+		return fmt.Errorf("Can't pivot root: %v", err)
+	}
+	if err := os.Chdir("/"); err != nil {
+		return err
+	}
+	if err := syscall.Unmount("/"+sandboxOldRootDirName, syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("Can't detach old root: %v", err)
+	}
+	_ = os.Remove("/" + sandboxOldRootDirName)
+	if err := syscall.Mount("", "/", "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID, ""); err != nil {
+		return fmt.Errorf("Can't remount sandbox root as read only: %v", err)
+	}
+	return os.Chdir(workingDir)
+}
+
+func dropPrivileges() error {
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prCapAmbient, prCapAmbientClearAll, 0, 0, 0, 0); errno != 0 {
+		return fmt.Errorf("Can't clear ambient capabilities: %v", errno)
+	}
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+		return fmt.Errorf("Can't set no new privileges: %v", errno)
+	}
+	return nil
+}
+
+func applyLimits(config *sandboxInitConfig) error {
+	if len(config.CgroupDir) != 0 {
+		if err := ioutil.WriteFile(path.Join(config.CgroupDir, "cgroup.procs"), []byte("0"), 0); err != nil {
+			return fmt.Errorf("Can't join cgroup: %v", err)
+		}
+	}
+	if config.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, config.Nice); err != nil {
+			return fmt.Errorf("Can't set nice value: %v", err)
+		}
+	}
+	if config.CPUTimeSeconds != 0 {
+		// SIGXCPU on the soft limit, SIGKILL on the hard limit
+		limit := syscall.Rlimit{Cur: config.CPUTimeSeconds, Max: config.CPUTimeSeconds + 1}
+		if err := syscall.Setrlimit(syscall.RLIMIT_CPU, &limit); err != nil {
+			return fmt.Errorf("Can't limit cpu time: %v", err)
+		}
+	}
+	if config.AddressSpace != 0 {
+		limit := syscall.Rlimit{Cur: config.AddressSpace, Max: config.AddressSpace}
+		if err := syscall.Setrlimit(syscall.RLIMIT_AS, &limit); err != nil {
+			return fmt.Errorf("Can't limit address space: %v", err)
+		}
+	}
+	return nil
+}
+
+func runSandboxInit(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("Unexpected sandbox init args %q", args)
+	}
+	config := sandboxInitConfig{}
+	if err := json.Unmarshal([]byte(args[1]), &config); err != nil {
+		return fmt.Errorf("Can't parse sandbox config: %v", err)
+	}
+	name := args[2]
+
+	executable, err := exec.LookPath(name)
+	if err != nil {
+		return err
+	}
+	if err = applyLimits(&config); err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	if config.UseNamespaces {
+		if err = setupSandbox(config.RootDir, config.WorkingDir, config.ReadOnlyDirs); err != nil {
+			return err
+		}
+		if err = dropPrivileges(); err != nil {
+			return err
+		}
+		env = make([]string, 0, len(env)+1)
+		for _, envVar := range os.Environ() {
+			if !strings.HasPrefix(envVar, "TMPDIR=") {
+				env = append(env, envVar)
+			}
+		}
+		env = append(env, "TMPDIR=/tmp")
+	}
+	return syscall.Exec(executable, append([]string{name}, args[3:]...), env)
+}
+
+// HandleSandboxInit sets up the sandbox and replaces the process, if it was launched by MakeCommand.
+func HandleSandboxInit() {
+	if len(os.Args) == 0 || os.Args[0] != sandboxInitName {
+		return
+	}
+	// prctl and setpriority affect only the calling thread, the thread must not be changed until exec,
+	// otherwise the compiler may get the thread which still has the ambient capabilities
+	runtime.LockOSThread()
+	err := runSandboxInit(os.Args)
+	fmt.Fprintln(os.Stderr, "popcorn sandbox:", err)
+	os.Exit(127)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -22,6 +23,20 @@ func (h *SHA256Struct) IsEmpty() bool {
 	return h.B0_7 == 0 && h.B8_15 == 0 && h.B16_23 == 0 && h.B24_31 == 0
 }
 
+// ToHex returns the digest as 64 hex chars.
+func (h *SHA256Struct) ToHex() string {
+	return fmt.Sprintf("%016x%016x%016x%016x", h.B0_7, h.B8_15, h.B16_23, h.B24_31)
+}
+
+// MakeSHA256StructFromHex parses the digest from 64 hex chars.
+func MakeSHA256StructFromHex(hexDigest string) (SHA256Struct, error) {
+	b, err := hex.DecodeString(hexDigest)
+	if err != nil || len(b) != 32 {
+		return SHA256Struct{}, fmt.Errorf("Bad sha256 digest %q", hexDigest)
+	}
+	return makeSHA256Struct(b), nil
+}
+
 func makeSHA256Struct(b []byte) SHA256Struct {
 	return SHA256Struct{
 		B0_7:   binary.BigEndian.Uint64(b[0:8]),
@@ -0,0 +1,330 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TraceParentKey is the grpc metadata key with the W3C trace context of the calling span.
+const TraceParentKey = "traceparent"
+
+// SpanKind values follow the OTLP span kinds.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// SpanContext identifies the span for its children, also in other processes.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+}
+
+// IsValid returns false for the empty context.
+func (spanContext SpanContext) IsValid() bool {
+	return spanContext.TraceID != [16]byte{} && spanContext.SpanID != [8]byte{}
+}
+
+// TraceParent formats the context as the W3C traceparent value.
+func (spanContext SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%x-%x-01", spanContext.TraceID, spanContext.SpanID)
+}
+
+// ParseTraceParent parses the W3C traceparent value, returns the empty context if it is malformed.
+func ParseTraceParent(traceParent string) SpanContext {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}
+	}
+	traceID, traceErr := hex.DecodeString(parts[1])
+	spanID, spanErr := hex.DecodeString(parts[2])
+	if traceErr != nil || spanErr != nil || len(traceID) != 16 || len(spanID) != 8 {
+		return SpanContext{}
+	}
+	spanContext := SpanContext{}
+	copy(spanContext.TraceID[:], traceID)
+	copy(spanContext.SpanID[:], spanID)
+	return spanContext
+}
+
+// OutgoingContextWithSpan passes the span context to the grpc server.
+func OutgoingContextWithSpan(ctx context.Context, span *Span) context.Context {
+	spanContext := span.Context()
+	if !spanContext.IsValid() {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, TraceParentKey, spanContext.TraceParent())
+}
+
+// SpanContextFromIncomingContext returns the span context of the grpc client, it is empty if the client doesn't trace.
+func SpanContextFromIncomingContext(ctx context.Context) SpanContext {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TraceParentKey); len(values) != 0 {
+			return ParseTraceParent(values[0])
+		}
+	}
+	return SpanContext{}
+}
+
+type spanAttribute struct {
+	key   string
+	value interface{}
+}
+
+// Span is a timed phase, nil spans are used if the tracing is disabled, all methods accept them.
+type Span struct {
+	tracer       *Tracer
+	context      SpanContext
+	parentSpanID [8]byte
+	name         string
+	kind         SpanKind
+	start        time.Time
+	end          time.Time
+	attributes   []spanAttribute
+	err          error
+}
+
+// Context ...
+func (span *Span) Context() SpanContext {
+	if span == nil {
+		return SpanContext{}
+	}
+	return span.context
+}
+
+// SetAttribute accepts string, bool and integer values, the others are formatted as strings.
+func (span *Span) SetAttribute(key string, value interface{}) {
+	if span != nil {
+		span.attributes = append(span.attributes, spanAttribute{key, value})
+	}
+}
+
+// SetError marks the span as failed.
+func (span *Span) SetError(err error) {
+	if span != nil && err != nil {
+		span.err = err
+	}
+}
+
+// StartChild ...
+func (span *Span) StartChild(name string, kind SpanKind) *Span {
+	if span == nil {
+		return nil
+	}
+	return span.tracer.StartSpan(name, kind, span.context)
+}
+
+// End finishes the span, it is exported with the next flush.
+func (span *Span) End() {
+	if span == nil {
+		return
+	}
+	span.end = time.Now()
+	span.tracer.mu.Lock()
+	span.tracer.finishedSpans = append(span.tracer.finishedSpans, span)
+	span.tracer.mu.Unlock()
+}
+
+// Tracer collects finished spans and exports them as OTLP JSON to files or to a collector.
+type Tracer struct {
+	serviceName string
+	exportDir   string
+	exportURL   string
+	httpClient  *http.Client
+
+	mu            sync.Mutex
+	finishedSpans []*Span
+}
+
+// MakeTracer returns nil if there is no export destination, so the tracing is disabled.
+func MakeTracer(serviceName string, exportDir string, exportURL string) *Tracer {
+	if len(exportDir) == 0 && len(exportURL) == 0 {
+		return nil
+	}
+	return &Tracer{
+		serviceName: serviceName,
+		exportDir:   exportDir,
+		exportURL:   strings.TrimRight(exportURL, "/"),
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// StartSpan starts a new trace if the parent context is empty.
+func (tracer *Tracer) StartSpan(name string, kind SpanKind, parent SpanContext) *Span {
+	if tracer == nil {
+		return nil
+	}
+	span := &Span{tracer: tracer, name: name, kind: kind, start: time.Now()}
+	if parent.IsValid() {
+		span.context.TraceID = parent.TraceID
+		span.parentSpanID = parent.SpanID
+	} else {
+		_, _ = rand.Read(span.context.TraceID[:])
+	}
+	_, _ = rand.Read(span.context.SpanID[:])
+	return span
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTracesData struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func makeOTLPAttribute(key string, value interface{}) otlpAttribute {
+	attribute := otlpAttribute{Key: key}
+	var intValue string
+	switch v := value.(type) {
+	case bool:
+		attribute.Value.BoolValue = &v
+		return attribute
+	case int:
+		intValue = strconv.FormatInt(int64(v), 10)
+	case int32:
+		intValue = strconv.FormatInt(int64(v), 10)
+	case int64:
+		intValue = strconv.FormatInt(v, 10)
+	case uint32:
+		intValue = strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		intValue = strconv.FormatUint(v, 10)
+	default:
+		stringValue := fmt.Sprint(v)
+		attribute.Value.StringValue = &stringValue
+		return attribute
+	}
+	attribute.Value.IntValue = &intValue
+	return attribute
+}
+
+func (span *Span) toOTLP() otlpSpan {
+	result := otlpSpan{
+		TraceID:           hex.EncodeToString(span.context.TraceID[:]),
+		SpanID:            hex.EncodeToString(span.context.SpanID[:]),
+		Name:              span.name,
+		Kind:              span.kind,
+		StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+	}
+	if span.parentSpanID != [8]byte{} {
+		result.ParentSpanID = hex.EncodeToString(span.parentSpanID[:])
+	}
+	for _, attribute := range span.attributes {
+		result.Attributes = append(result.Attributes, makeOTLPAttribute(attribute.key, attribute.value))
+	}
+	if span.err != nil {
+		result.Status = &otlpStatus{Code: 2, Message: span.err.Error()}
+	}
+	return result
+}
+
+func (tracer *Tracer) makeTracesData(spans []*Span) *otlpTracesData {
+	scopeSpans := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(spans))}
+	scopeSpans.Scope.Name = "popcorn"
+	scopeSpans.Scope.Version = GetVersion()
+	for _, span := range spans {
+		scopeSpans.Spans = append(scopeSpans.Spans, span.toOTLP())
+	}
+	hostname, _ := os.Hostname()
+	resourceSpans := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{scopeSpans}}
+	resourceSpans.Resource.Attributes = []otlpAttribute{
+		makeOTLPAttribute("service.name", tracer.serviceName),
+		makeOTLPAttribute("host.name", hostname),
+		makeOTLPAttribute("process.pid", os.Getpid()),
+	}
+	return &otlpTracesData{ResourceSpans: []otlpResourceSpans{resourceSpans}}
+}
+
+// Flush exports the finished spans, the export directory gets a new file per flush,
+// the collector gets them by OTLP/HTTP with JSON encoding.
+func (tracer *Tracer) Flush() error {
+	if tracer == nil {
+		return nil
+	}
+	tracer.mu.Lock()
+	spans := tracer.finishedSpans
+	tracer.finishedSpans = nil
+	tracer.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(tracer.makeTracesData(spans))
+	if err != nil {
+		return err
+	}
+	if len(tracer.exportDir) != 0 {
+		fileName := fmt.Sprintf("%s-%d-%d.json", tracer.serviceName, time.Now().UnixNano(), os.Getpid())
+		if err = WriteFile(filepath.Join(tracer.exportDir, fileName), data); err != nil {
+			return fmt.Errorf("Can't write trace file: %v", err)
+		}
+	}
+	if len(tracer.exportURL) != 0 {
+		response, err := tracer.httpClient.Post(tracer.exportURL+"/v1/traces", "application/json", bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("Can't send traces to collector: %v", err)
+		}
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("Can't send traces to collector: %s", response.Status)
+		}
+	}
+	return nil
+}
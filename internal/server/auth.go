@@ -0,0 +1,326 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Permission ...
+type Permission uint32
+
+const (
+	// PermissionCompile allows compilation sessions
+	PermissionCompile Permission = 1 << iota
+	// PermissionObjCacheRead allows getting compiled objects from cache
+	PermissionObjCacheRead
+	// PermissionObjCacheWrite allows saving compiled objects to cache
+	PermissionObjCacheWrite
+	// PermissionAdmin allows service and administration calls
+	PermissionAdmin
+	// PermissionInteractive allows the interactive compilation priority, other compilations get the normal one
+	PermissionInteractive
+	// PermissionPeer allows cluster servers to fetch and push objects of the shared object cache,
+	// it is granted to peers only and isn't a part of all
+	PermissionPeer
+
+	permissionAll = PermissionCompile | PermissionObjCacheRead | PermissionObjCacheWrite | PermissionAdmin | PermissionInteractive
+)
+
+var permissionNames = map[string]Permission{
+	"compile":         PermissionCompile,
+	"obj-cache-read":  PermissionObjCacheRead,
+	"obj-cache-write": PermissionObjCacheWrite,
+	"obj-cache":       PermissionObjCacheRead | PermissionObjCacheWrite,
+	"admin":           PermissionAdmin,
+	"interactive":     PermissionInteractive,
+	"peer":            PermissionPeer,
+	"all":             permissionAll,
+}
+
+// ParsePermissions parses comma separated permission names.
+func ParsePermissions(permissions string) (Permission, error) {
+	result := Permission(0)
+	for _, name := range strings.Split(permissions, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		permission, ok := permissionNames[name]
+		if !ok {
+			return 0, fmt.Errorf("Unknown permission %q", name)
+		}
+		result |= permission
+	}
+	return result, nil
+}
+
+func (p Permission) String() string {
+	names := make([]string, 0, 6)
+	for _, name := range []string{"compile", "obj-cache-read", "obj-cache-write", "admin", "interactive", "peer"} {
+		if p&permissionNames[name] != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// AuthToken ...
+type AuthToken struct {
+	Name        string
+	Permissions Permission
+	Expiration  time.Time
+}
+
+// HasPermission ...
+func (token *AuthToken) HasPermission(permission Permission) bool {
+	return token.Permissions&permission == permission
+}
+
+type authTokenContextKey struct{}
+
+// getAuthToken returns nil if authentication is disabled.
+func getAuthToken(ctx context.Context) *AuthToken {
+	token, _ := ctx.Value(authTokenContextKey{}).(*AuthToken)
+	return token
+}
+
+// hasPermission is true if authentication is disabled or the caller token has the permission.
+func hasPermission(ctx context.Context, permission Permission) bool {
+	token := getAuthToken(ctx)
+	return token == nil || token.HasPermission(permission)
+}
+
+// errAdminAuthRequired is returned to admin calls on the server without authentication.
+var errAdminAuthRequired = status.Error(codes.PermissionDenied,
+	"Admin calls require authentication, start the server with auth tokens or -allow-unauthenticated-admin")
+
+// checkAdminAccess refuses admin calls if the authentication is disabled, unless they are allowed explicitly.
+// With the authentication the admin permission is checked by the interceptors.
+func (s *CompilationServer) checkAdminAccess() error {
+	if s.Authenticator == nil && !s.AllowUnauthenticatedAdmin {
+		return errAdminAuthRequired
+	}
+	return nil
+}
+
+// errPeerCallsDisabled is returned to peer calls on the server outside of a cluster or without authentication.
+var errPeerCallsDisabled = status.Error(codes.PermissionDenied,
+	"Peer calls require -peers and authentication, the peers use tokens with the peer permission")
+
+// checkPeerAccess refuses peer calls unless the server is a part of a cluster with the authentication,
+// otherwise anyone could read or poison the object cache.
+// With the authentication the peer permission is checked by the interceptors.
+func (s *CompilationServer) checkPeerAccess() error {
+	if s.ObjectCachePeers == nil || s.Authenticator == nil {
+		return errPeerCallsDisabled
+	}
+	return nil
+}
+
+var methodPermissions = map[string]Permission{
+	"/popcorn.CompilationService/StartCompilationSession": PermissionCompile,
+	"/popcorn.CompilationService/TransferFile":            PermissionCompile,
+	"/popcorn.CompilationService/CompileSource":           PermissionCompile,
+	"/popcorn.CompilationService/CloseSession":            PermissionCompile,
+	"/popcorn.CompilationService/Status":                  0,
+	"/popcorn.CompilationService/Drain":                   PermissionAdmin,
+	"/popcorn.CompilationService/GetCacheStats":           PermissionAdmin,
+	"/popcorn.CompilationService/ListCacheEntries":        PermissionAdmin,
+	"/popcorn.CompilationService/EvictCacheEntries":       PermissionAdmin,
+	"/popcorn.CompilationService/ResizeCache":             PermissionAdmin,
+	"/popcorn.CompilationService/GetCachedObject":         PermissionPeer,
+	"/popcorn.CompilationService/PutCachedObject":         PermissionPeer,
+}
+
+const hmacTokenPrefix = "v1."
+
+// Authenticator checks bearer tokens from grpc metadata.
+// Tokens are either listed in the static tokens file or signed by the HMAC key.
+type Authenticator struct {
+	staticTokens map[string]*AuthToken
+	hmacKey      []byte
+
+	Rejected AtomicStat
+}
+
+func readStaticTokens(tokensFile string) (map[string]*AuthToken, error) {
+	file, err := os.Open(tokensFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tokens := make(map[string]*AuthToken, 16)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// <token> <name> <permissions>
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected '<token> <name> <permissions>'", tokensFile, lineNumber)
+		}
+		permissions, err := ParsePermissions(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", tokensFile, lineNumber, err)
+		}
+		tokens[fields[0]] = &AuthToken{Name: fields[1], Permissions: permissions}
+	}
+	return tokens, scanner.Err()
+}
+
+// MakeAuthenticator returns nil if neither tokens file nor HMAC key file is specified.
+func MakeAuthenticator(tokensFile string, hmacKeyFile string) (*Authenticator, error) {
+	if len(tokensFile) == 0 && len(hmacKeyFile) == 0 {
+		return nil, nil
+	}
+
+	authenticator := &Authenticator{}
+	if len(tokensFile) != 0 {
+		tokens, err := readStaticTokens(tokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read auth tokens: %v", err)
+		}
+		authenticator.staticTokens = tokens
+	}
+	if len(hmacKeyFile) != 0 {
+		key, err := ioutil.ReadFile(hmacKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read HMAC key: %v", err)
+		}
+		authenticator.hmacKey = []byte(strings.TrimSpace(string(key)))
+		if len(authenticator.hmacKey) == 0 {
+			return nil, fmt.Errorf("HMAC key file %q is empty", hmacKeyFile)
+		}
+	}
+	return authenticator, nil
+}
+
+func (a *Authenticator) sign(payload string) string {
+	mac := hmac.New(sha256.New, a.hmacKey)
+	_, _ = mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IssueToken makes a token signed by the HMAC key.
+func (a *Authenticator) IssueToken(name string, permissions Permission, ttl time.Duration) (string, error) {
+	if a == nil || len(a.hmacKey) == 0 {
+		return "", fmt.Errorf("HMAC key is required for issuing tokens")
+	}
+	if strings.ContainsAny(name, "\n") {
+		return "", fmt.Errorf("Token name can't contain new lines")
+	}
+	payload := fmt.Sprintf("%s\n%d\n%d", name, permissions, time.Now().Add(ttl).Unix())
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return hmacTokenPrefix + encodedPayload + "." + a.sign(encodedPayload), nil
+}
+
+func (a *Authenticator) checkHMACToken(token string) (*AuthToken, error) {
+	parts := strings.Split(token[len(hmacTokenPrefix):], ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	if subtle.ConstantTimeCompare([]byte(a.sign(parts[0])), []byte(parts[1])) != 1 {
+		return nil, fmt.Errorf("bad token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token payload")
+	}
+	fields := strings.Split(string(payload), "\n")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("malformed token payload")
+	}
+	permissions, errPermissions := strconv.ParseUint(fields[1], 10, 32)
+	expiration, errExpiration := strconv.ParseInt(fields[2], 10, 64)
+	if errPermissions != nil || errExpiration != nil {
+		return nil, fmt.Errorf("malformed token payload")
+	}
+
+	authToken := &AuthToken{Name: fields[0], Permissions: Permission(permissions), Expiration: time.Unix(expiration, 0)}
+	if time.Now().After(authToken.Expiration) {
+		return nil, fmt.Errorf("token of %q is expired", authToken.Name)
+	}
+	return authToken, nil
+}
+
+// Authenticate ...
+func (a *Authenticator) Authenticate(token string) (*AuthToken, error) {
+	if authToken := a.staticTokens[token]; authToken != nil {
+		return authToken, nil
+	}
+	if len(a.hmacKey) != 0 && strings.HasPrefix(token, hmacTokenPrefix) {
+		return a.checkHMACToken(token)
+	}
+	return nil, fmt.Errorf("unknown token")
+}
+
+func (a *Authenticator) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		a.Rejected.Increment()
+		return nil, status.Error(codes.Unauthenticated, "Bearer token is required")
+	}
+
+	authToken, err := a.Authenticate(strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil {
+		a.Rejected.Increment()
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication failed: %v", err)
+	}
+
+	requiredPermission, known := methodPermissions[fullMethod]
+	if !known {
+		requiredPermission = PermissionAdmin
+	}
+	if !authToken.HasPermission(requiredPermission) {
+		a.Rejected.Increment()
+		return nil, status.Errorf(codes.PermissionDenied, "%q has no permission for %s", authToken.Name, fullMethod)
+	}
+	return context.WithValue(ctx, authTokenContextKey{}, authToken), nil
+}
+
+// UnaryInterceptor ...
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+type authorizedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authorizedServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// StreamInterceptor ...
+func (a *Authenticator) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedServerStream{ServerStream: stream, ctx: ctx})
+}
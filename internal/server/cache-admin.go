@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"path"
+	"time"
+
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
+	"github.com/AlexK0/popcorn/internal/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *CompilationServer) getCacheStorage(cacheType pb.CacheType) (CacheStorage, error) {
+	switch cacheType {
+	case pb.CacheType_CACHE_OBJ:
+		return s.ObjFileCache, nil
+	case pb.CacheType_CACHE_SRC:
+		return s.SrcFileCache, nil
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "Unknown cache type %d", cacheType)
+}
+
+// getFileCache returns the local disk cache, the entries of remote caches are managed by the cache service.
+func (s *CompilationServer) getFileCache(cacheType pb.CacheType) (*FileCache, error) {
+	cache, err := s.getCacheStorage(cacheType)
+	if err != nil {
+		return nil, err
+	}
+	fileCache, ok := cache.(*FileCache)
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "Cache %s is remote, it is managed by the cache service", cacheType)
+	}
+	return fileCache, nil
+}
+
+func makeCacheEntriesFilter(in *pb.CacheEntriesFilter) (CacheEntriesFilter, error) {
+	filter := CacheEntriesFilter{
+		FileNamePattern: in.FileNamePattern,
+		MinAge:          time.Duration(in.MinAge),
+	}
+	if _, err := path.Match(filter.FileNamePattern, ""); err != nil {
+		return filter, status.Errorf(codes.InvalidArgument, "Bad file name pattern %q: %v", filter.FileNamePattern, err)
+	}
+	if in.Key != nil {
+		key := common.SHA256MessageToSHA256Struct(in.Key)
+		filter.Key = &key
+	}
+	return filter, nil
+}
+
+// getCacheHitsAndMisses returns lookups of required files for the src cache and lookups of objects for the obj cache.
+func (s *CompilationServer) getCacheHitsAndMisses(cacheType pb.CacheType) (int64, int64) {
+	if cacheType == pb.CacheType_CACHE_SRC {
+		return s.Stats.FilesFromSrcCache.Get(), s.Stats.TransferredFiles.Get()
+	}
+	return s.Stats.ObjCacheHits.Get() + s.Stats.ObjCachePeerHits.Get(), s.Stats.ObjCacheMisses.Get()
+}
+
+func (s *CompilationServer) makeCacheStats() []*pb.CacheStats {
+	caches := make([]*pb.CacheStats, 0, 2)
+	for _, cacheType := range []pb.CacheType{pb.CacheType_CACHE_SRC, pb.CacheType_CACHE_OBJ} {
+		cache, _ := s.getCacheStorage(cacheType)
+		cacheStats := &pb.CacheStats{
+			Cache:       cacheType,
+			FilesCount:  cache.GetFilesCount(),
+			BytesOnDisk: cache.GetBytesOnDisk(),
+			PurgedFiles: cache.GetPurgedFiles(),
+		}
+		cacheStats.Hits, cacheStats.Misses = s.getCacheHitsAndMisses(cacheType)
+		if fileCache, ok := cache.(*FileCache); ok {
+			cacheStats.OriginalBytes = fileCache.GetOriginalBytes()
+			cacheStats.SizeLimit = fileCache.GetSizeLimit()
+		} else {
+			cacheStats.Remote = true
+		}
+		caches = append(caches, cacheStats)
+	}
+	return caches
+}
+
+// GetCacheStats ...
+func (s *CompilationServer) GetCacheStats(ctx context.Context, in *pb.GetCacheStatsRequest) (*pb.GetCacheStatsReply, error) {
+	if err := s.checkAdminAccess(); err != nil {
+		return nil, err
+	}
+	return &pb.GetCacheStatsReply{Caches: s.makeCacheStats()}, nil
+}
+
+// ListCacheEntries ...
+func (s *CompilationServer) ListCacheEntries(ctx context.Context, in *pb.ListCacheEntriesRequest) (*pb.ListCacheEntriesReply, error) {
+	if err := s.checkAdminAccess(); err != nil {
+		return nil, err
+	}
+	if in.Filter == nil {
+		in.Filter = &pb.CacheEntriesFilter{}
+	}
+	fileCache, err := s.getFileCache(in.Filter.Cache)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := makeCacheEntriesFilter(in.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, matchedEntries := fileCache.ListEntries(filter, int(in.Limit))
+	reply := &pb.ListCacheEntriesReply{
+		Entries:        make([]*pb.CacheEntry, 0, len(entries)),
+		MatchedEntries: int64(matchedEntries),
+	}
+	for _, entry := range entries {
+		reply.Entries = append(reply.Entries, &pb.CacheEntry{
+			FileName:     entry.FileName,
+			Key:          common.SHA256StructToSHA256Message(entry.Key),
+			FileSize:     entry.FileSize,
+			OriginalSize: entry.OriginalSize,
+			Age:          int64(entry.Age),
+		})
+	}
+	return reply, nil
+}
+
+// EvictCacheEntries ...
+func (s *CompilationServer) EvictCacheEntries(ctx context.Context, in *pb.EvictCacheEntriesRequest) (*pb.EvictCacheEntriesReply, error) {
+	if err := s.checkAdminAccess(); err != nil {
+		return nil, err
+	}
+	if in.Filter == nil {
+		in.Filter = &pb.CacheEntriesFilter{}
+	}
+	fileCache, err := s.getFileCache(in.Filter.Cache)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := makeCacheEntriesFilter(in.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.IsEmpty() && !in.All {
+		return nil, status.Error(codes.InvalidArgument, "Empty filter matches the whole cache, the all flag is required")
+	}
+
+	evictedFiles, evictedBytes := fileCache.EvictEntries(filter)
+	cacheLog.Info("Evicted", evictedFiles, "files,", evictedBytes, "bytes from cache", in.Filter.Cache)
+	return &pb.EvictCacheEntriesReply{EvictedFiles: evictedFiles, EvictedBytes: evictedBytes}, nil
+}
+
+// ResizeCache ...
+func (s *CompilationServer) ResizeCache(ctx context.Context, in *pb.ResizeCacheRequest) (*pb.ResizeCacheReply, error) {
+	if err := s.checkAdminAccess(); err != nil {
+		return nil, err
+	}
+	fileCache, err := s.getFileCache(in.Cache)
+	if err != nil {
+		return nil, err
+	}
+	if in.SizeLimit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Bad cache size limit %d", in.SizeLimit)
+	}
+
+	purgedFiles := fileCache.GetPurgedFiles()
+	previousLimit := fileCache.SetSizeLimit(in.SizeLimit)
+	purgedFiles = fileCache.GetPurgedFiles() - purgedFiles
+	cacheLog.Info("Cache", in.Cache, "limit is changed from", previousLimit, "to", in.SizeLimit, "bytes,", purgedFiles, "files purged")
+	return &pb.ResizeCacheReply{PreviousSizeLimit: previousLimit, PurgedFiles: purgedFiles}, nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"io"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// cacheLog is the logger of the src and obj caches
+var cacheLog = common.MakeLogger("cache")
+
+// CacheStorage stores files by keys, FileCache keeps them on the local disk, HTTPCacheStorage in a remote cache service.
+type CacheStorage interface {
+	// CreateLinkFromCache places the cached file to the destination path, returns false if there is no such file.
+	CreateLinkFromCache(destPath string, key common.SHA256Struct) bool
+	// SaveFileToCache returns false if the file is already cached.
+	SaveFileToCache(srcPath string, key common.SHA256Struct, fileSize int64) (bool, error)
+	// RemoveFromCache returns false if there is no such file.
+	RemoveFromCache(fileName string, key common.SHA256Struct) bool
+	// OpenCachedFile returns nil if there is no such file.
+	OpenCachedFile(fileName string, key common.SHA256Struct) io.ReadCloser
+
+	// IsRemote is true if the lookups are requests to a cache service, they are not repeated for missing files.
+	IsRemote() bool
+
+	// PurgeLastElementsIfRequired and SaveIndexIfRequired are called periodically from the cron.
+	PurgeLastElementsIfRequired()
+	SaveIndexIfRequired()
+	// Close saves the storage state on the server shutdown.
+	Close() error
+
+	GetFilesCount() int64
+	GetBytesOnDisk() int64
+	GetPurgedFiles() int64
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +12,13 @@ import (
 type Client struct {
 	FileSHA256Cache
 	lastSeen int64
+
+	// UserName and Address are taken from the first session of the client, the user name is verified if the client has a certificate or a token
+	UserName string
+	Address  string
+
+	Compilations AtomicStat
+	CompilerTime AtomicStat
 }
 
 type Clients struct {
@@ -25,13 +33,17 @@ func MakeClients() *Clients {
 	}
 }
 
-func (clients *Clients) GetClient(userID common.SHA256Struct) *Client {
+func (clients *Clients) GetClient(userID common.SHA256Struct, userName string, address string) *Client {
 	clients.mu.RLock()
 	client := clients.table[userID]
 	clients.mu.RUnlock()
 
 	if client == nil {
-		newClient := &Client{FileSHA256Cache: FileSHA256Cache{table: make(map[string]fileMeta, 1024)}}
+		newClient := &Client{
+			FileSHA256Cache: FileSHA256Cache{table: make(map[string]fileMeta, 1024)},
+			UserName:        userName,
+			Address:         address,
+		}
 
 		clients.mu.Lock()
 		client = clients.table[userID]
@@ -52,6 +64,29 @@ func (clients *Clients) Count() int64 {
 	return int64(clientsCount)
 }
 
+// GetTopClients returns the clients with the most compilations.
+func (clients *Clients) GetTopClients(limit int) []*Client {
+	clients.mu.RLock()
+	topClients := make([]*Client, 0, len(clients.table))
+	for _, client := range clients.table {
+		topClients = append(topClients, client)
+	}
+	clients.mu.RUnlock()
+
+	sort.Slice(topClients, func(i, j int) bool {
+		return topClients[i].Compilations.Get() > topClients[j].Compilations.Get()
+	})
+	if len(topClients) > limit {
+		topClients = topClients[:limit]
+	}
+	return topClients
+}
+
+// LastSeen ...
+func (client *Client) LastSeen() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&client.lastSeen))
+}
+
 func (clients *Clients) GetRandomClientCacheSize() int64 {
 	clients.mu.RLock()
 	defer clients.mu.RUnlock()
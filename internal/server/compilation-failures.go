@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// compilationFailureOutputLimit is the maximal length of the compiler output kept for a failure
+const compilationFailureOutputLimit = 512
+
+// CompilationFailure describes a compilation which finished with an error or a non-zero compiler exit code.
+type CompilationFailure struct {
+	Time           time.Time
+	SessionID      uint64
+	SourceFilePath string
+	Compiler       string
+	ClientUserName string
+	ExitCode       int
+	Error          string
+	// Output is the beginning of the compiler stderr
+	Output string
+}
+
+// CompilationFailures keeps the most recent compilation failures.
+type CompilationFailures struct {
+	failures []CompilationFailure
+	next     int
+	mu       sync.Mutex
+}
+
+// MakeCompilationFailures ...
+func MakeCompilationFailures(capacity int) *CompilationFailures {
+	return &CompilationFailures{failures: make([]CompilationFailure, 0, capacity)}
+}
+
+// AddSession records the failure of the session compilation.
+func (failures *CompilationFailures) AddSession(session *ClientSession) {
+	failure := CompilationFailure{
+		Time:           time.Now(),
+		SessionID:      session.SessionID,
+		SourceFilePath: session.SourceFilePath,
+		Compiler:       session.Compiler,
+		ClientUserName: session.ClientUserName,
+		ExitCode:       session.CompilerExitCode,
+	}
+	if session.CompilationError != nil {
+		failure.Error = session.CompilationError.Error()
+	}
+	output := bytes.TrimSpace(session.CompilerStderr)
+	if len(output) > compilationFailureOutputLimit {
+		output = output[:compilationFailureOutputLimit]
+	}
+	failure.Output = string(output)
+
+	failures.mu.Lock()
+	if len(failures.failures) < cap(failures.failures) {
+		failures.failures = append(failures.failures, failure)
+	} else {
+		failures.failures[failures.next] = failure
+	}
+	failures.next = (failures.next + 1) % cap(failures.failures)
+	failures.mu.Unlock()
+}
+
+// GetRecent returns the failures from the newest to the oldest.
+func (failures *CompilationFailures) GetRecent() []CompilationFailure {
+	failures.mu.Lock()
+	defer failures.mu.Unlock()
+	recent := make([]CompilationFailure, 0, len(failures.failures))
+	for i := 1; i <= len(failures.failures); i++ {
+		recent = append(recent, failures.failures[(failures.next-i+len(failures.failures))%len(failures.failures)])
+	}
+	return recent
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
+)
+
+// compilationPriorities are ordered from the highest priority to the lowest one.
+var compilationPriorities = []pb.CompilationPriority{
+	pb.CompilationPriority_PRIORITY_INTERACTIVE,
+	pb.CompilationPriority_PRIORITY_NORMAL,
+	pb.CompilationPriority_PRIORITY_BATCH,
+}
+
+// CompilerNiceValues are nice values of compilers for each priority.
+var CompilerNiceValues = map[pb.CompilationPriority]int{
+	pb.CompilationPriority_PRIORITY_INTERACTIVE: 0,
+	pb.CompilationPriority_PRIORITY_NORMAL:      5,
+	pb.CompilationPriority_PRIORITY_BATCH:       10,
+}
+
+type queuedCompilation struct {
+	ready     chan struct{}
+	cancelled bool
+}
+
+type clientQueue struct {
+	client  *Client
+	waiting []*queuedCompilation
+}
+
+// priorityClass serves clients in round robin order, so one client can't starve others.
+type priorityClass struct {
+	clients  []*clientQueue
+	byClient map[*Client]*clientQueue
+}
+
+func (class *priorityClass) push(client *Client, compilation *queuedCompilation) {
+	queue := class.byClient[client]
+	if queue == nil {
+		queue = &clientQueue{client: client}
+		class.byClient[client] = queue
+		class.clients = append(class.clients, queue)
+	}
+	queue.waiting = append(queue.waiting, compilation)
+}
+
+func (class *priorityClass) pop() *queuedCompilation {
+	if len(class.clients) == 0 {
+		return nil
+	}
+	queue := class.clients[0]
+	class.clients[0] = nil
+	class.clients = class.clients[1:]
+
+	compilation := queue.waiting[0]
+	queue.waiting[0] = nil
+	queue.waiting = queue.waiting[1:]
+	if len(queue.waiting) == 0 {
+		delete(class.byClient, queue.client)
+	} else {
+		class.clients = append(class.clients, queue)
+	}
+	return compilation
+}
+
+// CompilationQueue limits amount of concurrently running compilers, other compilations wait in the queue.
+// Compilations with higher priority go first, the clients with the same priority are served fairly.
+type CompilationQueue struct {
+	maxRunning int
+	running    int
+	waiting    int
+	classes    map[pb.CompilationPriority]*priorityClass
+	mu         sync.Mutex
+
+	WaitTime           AtomicStat
+	QueuedCompilations AtomicStat
+}
+
+// MakeCompilationQueue ...
+func MakeCompilationQueue(maxRunning int) *CompilationQueue {
+	if maxRunning < 1 {
+		maxRunning = 1
+	}
+	queue := &CompilationQueue{
+		maxRunning: maxRunning,
+		classes:    make(map[pb.CompilationPriority]*priorityClass, len(compilationPriorities)),
+	}
+	for _, priority := range compilationPriorities {
+		queue.classes[priority] = &priorityClass{byClient: make(map[*Client]*clientQueue, 64)}
+	}
+	return queue
+}
+
+// Acquire blocks until the compilation can be started and returns the time spent in the queue.
+// If the context is done before, the compilation leaves the queue and the error is returned.
+func (queue *CompilationQueue) Acquire(ctx context.Context, client *Client, priority pb.CompilationPriority) (time.Duration, error) {
+	class := queue.classes[priority]
+	if class == nil {
+		class = queue.classes[pb.CompilationPriority_PRIORITY_NORMAL]
+	}
+
+	queue.mu.Lock()
+	if queue.running < queue.maxRunning {
+		queue.running++
+		queue.mu.Unlock()
+		return 0, nil
+	}
+	compilation := &queuedCompilation{ready: make(chan struct{})}
+	class.push(client, compilation)
+	queue.waiting++
+	queue.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case <-compilation.ready:
+	case <-ctx.Done():
+		queue.mu.Lock()
+		select {
+		case <-compilation.ready:
+			// The slot has been passed already, give it to the next one
+			queue.releaseLocked()
+		default:
+			compilation.cancelled = true
+			queue.waiting--
+		}
+		queue.mu.Unlock()
+		return time.Since(start), ctx.Err()
+	}
+	waitTime := time.Since(start)
+
+	queue.QueuedCompilations.Increment()
+	queue.WaitTime.AddDuration(waitTime)
+	return waitTime, nil
+}
+
+// Release passes the slot of the finished compilation to the next one in the queue.
+func (queue *CompilationQueue) Release() {
+	queue.mu.Lock()
+	queue.releaseLocked()
+	queue.mu.Unlock()
+}
+
+func (queue *CompilationQueue) releaseLocked() {
+	for _, priority := range compilationPriorities {
+		class := queue.classes[priority]
+		for next := class.pop(); next != nil; next = class.pop() {
+			if next.cancelled {
+				continue
+			}
+			queue.waiting--
+			close(next.ready)
+			return
+		}
+	}
+	queue.running--
+}
+
+// RunningCompilations ...
+func (queue *CompilationQueue) RunningCompilations() int64 {
+	queue.mu.Lock()
+	running := queue.running
+	queue.mu.Unlock()
+	return int64(running)
+}
+
+// QueueLength ...
+func (queue *CompilationQueue) QueueLength() int64 {
+	queue.mu.Lock()
+	length := queue.waiting
+	queue.mu.Unlock()
+	return int64(length)
+}
+
+// MaxRunningCompilations ...
+func (queue *CompilationQueue) MaxRunningCompilations() int64 {
+	return int64(queue.maxRunning)
+}
@@ -1,12 +1,14 @@
 package server
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
-	"os/exec"
+	"path"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -14,41 +16,125 @@ import (
 	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
 	"github.com/AlexK0/popcorn/internal/common"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CompilationServer struct {
 	pb.UnimplementedCompilationServiceServer
 
 	StartTime time.Time
+	// ServerName identifies the server for clients
+	ServerName string
 
 	SessionsDir string
 
 	GRPCServer *grpc.Server
 
+	Authenticator *Authenticator
+	// AllowUnauthenticatedAdmin allows admin calls if the authentication is disabled
+	AllowUnauthenticatedAdmin bool
+	CompilerPolicy            *CompilerPolicy
+
+	CompilerIdentities *CompilerIdentities
+
 	RemoteClients  *Clients
 	UploadingFiles *FileTransferring
 	SystemHeaders  *SystemHeaderCache
-	SrcFileCache   *FileCache
-	ObjFileCache   *FileCache
+	SrcFileCache   CacheStorage
+	ObjFileCache   CacheStorage
+
+	// ObjectCachePeers is nil if the server isn't a part of a cluster
+	ObjectCachePeers *ObjectCachePeers
+
+	ActiveSessions   *Sessions
+	CompilationQueue *CompilationQueue
+	Sandbox          *common.Sandbox
+	CompilerLimits   CompilerLimits
+
+	// CompileSourceWaitTimeout is how long a started compilation waits for the client's CompileSource call
+	CompileSourceWaitTimeout time.Duration
+	// SessionIdleTTL is how long a session may live without client activity
+	SessionIdleTTL time.Duration
 
-	ActiveSessions *Sessions
+	// DrainTimeout is how long the draining server waits for active sessions, unlimited if zero
+	DrainTimeout  time.Duration
+	draining      int32
+	drainDeadline int64
 
 	Stats *CompilationServerStats
+	// RecentFailures are shown on the status page
+	RecentFailures *CompilationFailures
+	// Tracer is nil if the tracing is disabled
+	Tracer *common.Tracer
 }
 
 func (s *CompilationServer) startCompilationIfPossible(session *ClientSession, dependencies int) {
 	if atomic.AddInt32(&session.CompilationStartDependencies, int32(dependencies)) == 0 {
-		session.CompilationWaitFinish.Add(1)
+		if !session.startCompilation() {
+			return
+		}
 		go s.performCompilation(session)
+		if s.CompileSourceWaitTimeout > 0 {
+			session.startAbandonTimer(s.CompileSourceWaitTimeout, func() { s.closeAbandonedSession(session) })
+		}
+	}
+}
+
+func (s *CompilationServer) closeAbandonedSession(session *ClientSession) {
+	abandoned, cleanup := session.markClosedIfAbandoned()
+	if !abandoned {
+		return
+	}
+	s.Stats.AbandonedSessions.Increment()
+	session.Log.WithPhase("wait_compile_source").Warning("Close session which compilation result isn't requested in", s.CompileSourceWaitTimeout)
+	s.ActiveSessions.CloseSession(session.SessionID)
+	if cleanup {
+		s.cleanupSession(session)
+	}
+}
+
+// leaveSession unregisters the rpc from the session and cleans the session up if it is the last call of the closed session.
+func (s *CompilationServer) leaveSession(session *ClientSession) {
+	if session.leaveCall() {
+		s.cleanupSession(session)
+	}
+}
+
+// getAllowedPriority downgrades unknown priorities and the interactive one without the permission to the normal priority.
+func getAllowedPriority(ctx context.Context, priority pb.CompilationPriority) pb.CompilationPriority {
+	if _, known := CompilerNiceValues[priority]; !known {
+		return pb.CompilationPriority_PRIORITY_NORMAL
+	}
+	if priority == pb.CompilationPriority_PRIORITY_INTERACTIVE && !hasPermission(ctx, PermissionInteractive) {
+		return pb.CompilationPriority_PRIORITY_NORMAL
 	}
+	return priority
 }
 
 func (s *CompilationServer) StartCompilationSession(ctx context.Context, in *pb.StartCompilationSessionRequest) (*pb.StartCompilationSessionReply, error) {
-	callObserver := s.Stats.StartCompilationSession.StartRPCCall()
-	sessionID, session := s.ActiveSessions.OpenNewSession(in, s.SessionsDir, s.RemoteClients.GetClient(common.SHA256MessageToSHA256Struct(in.ClientID)))
+	span := s.Tracer.StartSpan("StartCompilationSession", common.SpanKindServer, common.SpanContextFromIncomingContext(ctx))
+	callObserver := s.Stats.StartCompilationSession.StartRPCCall().WithSpan(span)
+	if s.IsDraining() {
+		return nil, callObserver.FinishWithError(errDraining)
+	}
+	if err := s.CompilerPolicy.CheckCompiler(in.Compiler); err != nil {
+		return nil, callObserver.FinishWithError(status.Error(codes.PermissionDenied, err.Error()))
+	}
+	if err := s.CompilerPolicy.CheckCompilerArgs(in.CompilerArgs); err != nil {
+		return nil, callObserver.FinishWithError(status.Error(codes.PermissionDenied, err.Error()))
+	}
+
+	clientID := getClientID(ctx, common.SHA256MessageToSHA256Struct(in.ClientID))
+	clientUserName := getClientUserName(ctx, in.ClientUserName)
+	sessionID, session := s.ActiveSessions.OpenNewSession(in, s.SessionsDir, clientUserName, s.RemoteClients.GetClient(clientID, clientUserName, getClientAddress(ctx)),
+		getAllowedPriority(ctx, in.Priority), hasPermission(ctx, PermissionObjCacheRead), hasPermission(ctx, PermissionObjCacheWrite), span.Context())
+	defer s.leaveSession(session)
+	span.SetAttribute("session.id", sessionID)
+	span.SetAttribute("source", in.SourceFilePath)
 
 	if err := os.MkdirAll(session.WorkingDir, os.ModePerm); err != nil {
-		s.ActiveSessions.CloseSession(sessionID)
+		s.closeSession(session)
 		return nil, callObserver.FinishWithError(fmt.Errorf("Can't create session working directory: %v", err))
 	}
 
@@ -59,14 +145,19 @@ func (s *CompilationServer) StartCompilationSession(ctx context.Context, in *pb.
 			continue
 		}
 		if s.SystemHeaders.IsSystemHeader(fileMetadata.FilePath, fileMetadata.FileSize, fileMetadata.SHA256Struct) {
+			atomic.AddInt32(&session.SystemHeaders, 1)
 			continue
 		}
 		if s.SrcFileCache.CreateLinkFromCache(fileMetadata.AbsPathInWorkingDir, fileMetadata.SHA256Struct) {
+			atomic.AddInt32(&session.FilesFromSrcCache, 1)
+			s.Stats.FilesFromSrcCache.Increment()
 			continue
 		}
 		requiredFiles = append(requiredFiles, &pb.RequiredFile{FileIndex: uint32(index), Status: pb.RequiredStatus_FULL_COPY_REQUIRED})
 	}
 
+	span.SetAttribute("files.required", len(in.RequiredFiles))
+	span.SetAttribute("files.missing", len(requiredFiles))
 	s.startCompilationIfPossible(session, len(requiredFiles))
 	return &pb.StartCompilationSessionReply{
 		SessionID:     sessionID,
@@ -100,7 +191,8 @@ func saveFileFromStream(file *os.File, stream pb.CompilationService_TransferFile
 }
 
 func (s *CompilationServer) TransferFile(stream pb.CompilationService_TransferFileServer) error {
-	callObserver := s.Stats.TransferFile.StartRPCCall()
+	span := s.Tracer.StartSpan("TransferFile", common.SpanKindServer, common.SpanContextFromIncomingContext(stream.Context()))
+	callObserver := s.Stats.TransferFile.StartRPCCall().WithSpan(span)
 	request, err := stream.Recv()
 	if err != nil {
 		return callObserver.FinishWithError(fmt.Errorf("Unexpected error: %v", err))
@@ -115,12 +207,22 @@ func (s *CompilationServer) TransferFile(stream pb.CompilationService_TransferFi
 	if session == nil {
 		return callObserver.FinishWithError(fmt.Errorf("Unknown SessionID %d", metadata.SessionID))
 	}
+	if !session.enterCall() {
+		return callObserver.FinishWithError(status.Errorf(codes.Canceled, "Session %d is closed", metadata.SessionID))
+	}
+	defer s.leaveSession(session)
+	session.Touch()
+	defer session.Touch()
 
 	fileMetadata := &session.RequiredFilesMeta[metadata.FileIndex]
+	span.SetAttribute("session.id", session.SessionID)
+	span.SetAttribute("file.path", fileMetadata.FilePath)
+	span.SetAttribute("file.size", fileMetadata.FileSize)
 	if metadata.FileSHA256 != nil {
 		fileMetadata.SHA256Struct = common.SHA256MessageToSHA256Struct(metadata.FileSHA256)
 		session.ClientInfo.FileSHA256Cache.SetFileSHA256(fileMetadata.FilePath, fileMetadata.MTime, fileMetadata.FileSize, fileMetadata.SHA256Struct)
 		if s.SystemHeaders.IsSystemHeader(fileMetadata.FilePath, fileMetadata.FileSize, fileMetadata.SHA256Struct) {
+			atomic.AddInt32(&session.SystemHeaders, 1)
 			s.startCompilationIfPossible(session, -1)
 			_ = stream.Send(&pb.TransferFileReply{Status: pb.RequiredStatus_DONE})
 			return callObserver.Finish()
@@ -130,12 +232,22 @@ func (s *CompilationServer) TransferFile(stream pb.CompilationService_TransferFi
 	}
 
 	start := time.Now()
+	// The span shows the waiting for the same file uploaded by another client
+	var waitSpan *common.Span
+	// The local cache is polled while another client uploads the same file, a miss of the remote cache is final.
+	// The file with the known sha256 is missed in the cache on the session start.
+	srcCacheMissed := s.SrcFileCache.IsRemote() && metadata.FileSHA256 == nil
 	for {
-		if s.SrcFileCache.CreateLinkFromCache(fileMetadata.AbsPathInWorkingDir, fileMetadata.SHA256Struct) {
+		if !srcCacheMissed && s.SrcFileCache.CreateLinkFromCache(fileMetadata.AbsPathInWorkingDir, fileMetadata.SHA256Struct) {
+			waitSpan.End()
+			span.SetAttribute("file.from_src_cache", true)
+			atomic.AddInt32(&session.FilesFromSrcCache, 1)
+			s.Stats.FilesFromSrcCache.Increment()
 			s.startCompilationIfPossible(session, -1)
 			_ = stream.Send(&pb.TransferFileReply{Status: pb.RequiredStatus_DONE})
 			return callObserver.Finish()
 		}
+		srcCacheMissed = s.SrcFileCache.IsRemote()
 		if s.UploadingFiles.StartFileTransfer(fileMetadata.FilePath, fileMetadata.SHA256Struct) {
 			_ = stream.Send(&pb.TransferFileReply{Status: pb.RequiredStatus_FULL_COPY_REQUIRED})
 			break
@@ -147,17 +259,25 @@ func (s *CompilationServer) TransferFile(stream pb.CompilationService_TransferFi
 			s.Stats.ForceFileTransferring.Increment()
 			break
 		}
+		if waitSpan == nil {
+			waitSpan = span.StartChild("WaitConcurrentTransfer", common.SpanKindInternal)
+		}
 		// TODO Why 100 milliseconds?
 		time.Sleep(100 * time.Millisecond)
 	}
+	waitSpan.End()
 
 	defer s.UploadingFiles.FinishFileTransfer(fileMetadata.FilePath, fileMetadata.SHA256Struct)
+	transferStart := time.Now()
 	fileTmp, err := common.OpenTempFile(fileMetadata.AbsPathInWorkingDir)
 	if err != nil {
 		return callObserver.FinishWithError(fmt.Errorf("Can't open temp file for saving transferring file: %v", err))
 	}
 
+	receiveSpan := span.StartChild("ReceiveFile", common.SpanKindInternal)
 	transferredBytes, err := saveFileFromStream(fileTmp, stream)
+	receiveSpan.SetError(err)
+	receiveSpan.End()
 	fileTmp.Close()
 	clearTmpAndFinish := func(err error) error {
 		os.Remove(fileTmp.Name())
@@ -173,73 +293,183 @@ func (s *CompilationServer) TransferFile(stream pb.CompilationService_TransferFi
 		return clearTmpAndFinish(fmt.Errorf("Can't rename temp file: %v", err))
 	}
 
+	atomic.AddInt32(&session.UploadedFiles, 1)
+	s.Stats.FileTransferTime.ObserveDuration(time.Since(transferStart))
+	s.Stats.TransferredFileSize.Observe(transferredBytes)
 	s.startCompilationIfPossible(session, -1)
 	_ = stream.Send(&pb.TransferFileReply{Status: pb.RequiredStatus_DONE})
 	_, _ = s.SrcFileCache.SaveFileToCache(fileMetadata.AbsPathInWorkingDir, fileMetadata.SHA256Struct, fileMetadata.FileSize)
 
 	s.Stats.TransferredFiles.Increment()
-	common.LogInfo("File", fileMetadata.FilePath, "successfully transferred")
+	session.Log.WithPhase("transfer").Info("File", fileMetadata.FilePath, "successfully transferred")
 	return callObserver.Finish()
 }
 
-func (s *CompilationServer) closeSession(session *ClientSession, sessionID uint64, close bool) {
-	if close {
-		s.ActiveSessions.CloseSession(sessionID)
-		_ = os.RemoveAll(session.WorkingDir)
+// closeSession kills the compiler of the session if it is still running and removes the session files.
+// The files are removed by the last call of the session if the session is in use.
+func (s *CompilationServer) closeSession(session *ClientSession) {
+	cleanup := session.markClosed()
+	s.ActiveSessions.CloseSession(session.SessionID)
+	if cleanup {
+		s.cleanupSession(session)
 	}
 }
 
-func (s *CompilationServer) performCompilation(session *ClientSession) {
-	objSHA256 := common.SHA256Struct{}
-	objExtraKey := ""
-	if session.UseObjectCache {
-		objSHA256, objExtraKey = session.MakeObjectCacheKey()
-		if s.ObjFileCache.CreateLinkFromCacheExtra(session.OutObjectFilePath, objSHA256, objExtraKey) {
-			common.LogInfo("Get obj from cache", session.OutObjectFilePath)
-			session.CompilationWaitFinish.Done()
-			return
-		}
+// cleanupSession waits for the cancelled compilation and removes the session files, it is called once after closing.
+func (s *CompilationServer) cleanupSession(session *ClientSession) {
+	session.CompilationWaitFinish.Wait()
+	_ = os.RemoveAll(session.WorkingDir)
+}
+
+// ReapIdleSessions closes sessions abandoned by clients, the files are removed in background.
+// A session which gets a call after the check is only cancelled, the call removes its files.
+func (s *CompilationServer) ReapIdleSessions() {
+	if s.SessionIdleTTL <= 0 {
+		return
 	}
+	for _, session := range s.ActiveSessions.GetIdleSessions(s.SessionIdleTTL) {
+		session.Log.WithPhase("reap").Warning("Reap session idle for", session.IdleTime())
+		s.ActiveSessions.CloseSession(session.SessionID)
+		s.Stats.ReapedSessions.Increment()
+		go s.closeSession(session)
+	}
+}
 
-	compilerProc := exec.Command(session.Compiler, session.RemoveUnusedIncludeDirsAndGetCompilerArgs()...)
-	compilerProc.Dir = session.WorkingDir
-	var compilerStderrBuff, compilerStdoutBuff bytes.Buffer
-	compilerProc.Stderr = &compilerStderrBuff
-	compilerProc.Stdout = &compilerStdoutBuff
+func (s *CompilationServer) performCompilation(session *ClientSession) {
+	defer session.CompilationWaitFinish.Done()
+	defer session.SetState(SessionStateCompiled)
+	if session.IsCancelled() {
+		return
+	}
 
-	common.LogInfo("Launch compiler:", compilerProc.Args)
-	_ = compilerProc.Run()
+	span := s.Tracer.StartSpan("Compilation", common.SpanKindInternal, session.TraceContext)
+	span.SetAttribute("session.id", session.SessionID)
+	defer func() {
+		span.SetAttribute("obj_cache.result", session.ObjectCacheResult.String())
+		span.SetError(session.CompilationError)
+		span.End()
+		if session.CompilationError != nil || session.CompilerExitCode != 0 {
+			s.Stats.FailedCompilations.Increment()
+			s.RecentFailures.AddSession(session)
+		}
+	}()
 
-	session.CompilerExitCode = compilerProc.ProcessState.ExitCode()
-	session.CompilerStdout = compilerStdoutBuff.Bytes()
-	session.CompilerStderr = compilerStderrBuff.Bytes()
+	objCacheKey := common.SHA256Struct{}
+	if session.ReadObjectCache || session.WriteObjectCache {
+		objCacheKey = session.MakeObjectCacheKey(s.CompilerIdentities.GetCompilerIdentity(session.Compiler))
+	}
+	lookupSpan := span.StartChild("ObjectCacheLookup", common.SpanKindInternal)
+	cacheHit := session.ReadObjectCache && s.ObjFileCache.CreateLinkFromCache(session.OutObjectFilePath, objCacheKey)
+	lookupSpan.End()
+	if cacheHit {
+		session.Log.WithPhase("obj_cache").Info("Get obj from cache", session.OutObjectFilePath)
+		session.ObjectCacheResult = pb.ObjectCacheResult_OBJ_CACHE_RESULT_HIT
+		s.Stats.ObjCacheHits.Increment()
+		return
+	}
+	if session.ReadObjectCache && s.ObjectCachePeers != nil {
+		peerSpan := span.StartChild("PeerCacheFetch", common.SpanKindInternal)
+		peerHit := s.ObjectCachePeers.FetchObject(session.OutObjectFilePath, objCacheKey)
+		peerSpan.End()
+		if peerHit {
+			session.Log.WithPhase("obj_cache").Info("Get obj from peer cache", session.OutObjectFilePath)
+			if stat, err := os.Stat(session.OutObjectFilePath); err == nil && session.WriteObjectCache {
+				_, _ = s.ObjFileCache.SaveFileToCache(session.OutObjectFilePath, objCacheKey, stat.Size())
+			}
+			session.ObjectCacheResult = pb.ObjectCacheResult_OBJ_CACHE_RESULT_PEER_HIT
+			s.Stats.ObjCachePeerHits.Increment()
+			return
+		}
+	}
+	if session.ReadObjectCache {
+		session.ObjectCacheResult = pb.ObjectCacheResult_OBJ_CACHE_RESULT_MISS
+		s.Stats.ObjCacheMisses.Increment()
+	}
 
-	if session.CompilerExitCode == 0 && len(session.CompilerStdout) == 0 && len(session.CompilerStderr) == 0 && session.UseObjectCache {
+	session.SetState(SessionStateQueued)
+	queueSpan := span.StartChild("QueueWait", common.SpanKindInternal)
+	queueWaitTime, err := s.CompilationQueue.Acquire(session.ctx, session.ClientInfo, session.Priority)
+	queueSpan.End()
+	session.QueueWaitTime = queueWaitTime
+	s.Stats.QueueWaitTime.ObserveDuration(queueWaitTime)
+	if err != nil {
+		s.Stats.CancelledCompilations.Increment()
+		session.CompilationError = status.Errorf(codes.Canceled, "Compilation of %q is cancelled in the queue", session.SourceFilePath)
+		return
+	}
+	session.SetState(SessionStateCompiling)
+	compileStart := time.Now()
+	compilerSpan := span.StartChild("CompilerRun", common.SpanKindInternal)
+	s.runCompiler(session)
+	compilerSpan.SetAttribute("compiler.exit_code", session.CompilerExitCode)
+	compilerSpan.End()
+	session.CompileTime = time.Since(compileStart)
+	s.Stats.CompilerRunTime.ObserveDuration(session.CompileTime)
+	session.ClientInfo.Compilations.Increment()
+	session.ClientInfo.CompilerTime.AddDuration(session.CompileTime)
+	s.CompilationQueue.Release()
+
+	if session.CompilerExitCode == 0 && len(session.CompilerStdout) == 0 && len(session.CompilerStderr) == 0 && session.WriteObjectCache {
 		if stat, err := os.Stat(session.OutObjectFilePath); err == nil {
-			_, _ = s.ObjFileCache.SaveFileToCacheExtra(session.OutObjectFilePath, objSHA256, objExtraKey, stat.Size())
+			if session.OverwriteObjectCache {
+				s.ObjFileCache.RemoveFromCache(path.Base(session.OutObjectFilePath), objCacheKey)
+			}
+			saved, _ := s.ObjFileCache.SaveFileToCache(session.OutObjectFilePath, objCacheKey, stat.Size())
+			if saved && s.ObjectCachePeers != nil {
+				s.ObjectCachePeers.PushObject(s.ObjFileCache, path.Base(session.OutObjectFilePath), objCacheKey)
+			}
 		}
 	}
-
-	session.CompilationWaitFinish.Done()
 }
 
 func (s *CompilationServer) CompileSource(in *pb.CompileSourceRequest, stream pb.CompilationService_CompileSourceServer) error {
-	callObserver := s.Stats.CompileSource.StartRPCCall()
+	span := s.Tracer.StartSpan("CompileSource", common.SpanKindServer, common.SpanContextFromIncomingContext(stream.Context()))
+	span.SetAttribute("session.id", in.SessionID)
+	callObserver := s.Stats.CompileSource.StartRPCCall().WithSpan(span)
 
 	session := s.ActiveSessions.GetSession(in.SessionID)
 	if session == nil {
 		return callObserver.FinishWithError(fmt.Errorf("Unknown session %d", in.SessionID))
 	}
 
-	defer s.closeSession(session, in.SessionID, in.CloseSessionAfterBuild)
+	if !session.enterCall() {
+		return callObserver.FinishWithError(status.Errorf(codes.Canceled, "Session %d is closed", in.SessionID))
+	}
+	defer s.leaveSession(session)
+	if !session.startCompileSource() {
+		return callObserver.FinishWithError(status.Errorf(codes.Canceled, "Session %d is closed", in.SessionID))
+	}
+	session.Touch()
+	if in.CloseSessionAfterBuild {
+		defer s.closeSession(session)
+	}
 
-	if waitingFiles := atomic.LoadInt32(&session.CompilationStartDependencies); waitingFiles != 0 {
+	if !session.isCompilationStarted() {
+		waitingFiles := atomic.LoadInt32(&session.CompilationStartDependencies)
 		return callObserver.FinishWithError(fmt.Errorf("Session %d is waiting %d files", in.SessionID, waitingFiles))
 	}
 
-	session.CompilationWaitFinish.Wait()
+	waitSpan := span.StartChild("WaitCompilation", common.SpanKindInternal)
+	compilationFinished := make(chan struct{})
+	go func() {
+		session.CompilationWaitFinish.Wait()
+		close(compilationFinished)
+	}()
+	select {
+	case <-compilationFinished:
+		waitSpan.End()
+	case <-stream.Context().Done():
+		waitSpan.End()
+		session.Log.WithPhase("compile_source").Info("Client has gone, cancel session")
+		s.closeSession(session)
+		return callObserver.FinishWithError(status.FromContextError(stream.Context().Err()).Err())
+	}
+	if session.CompilationError != nil {
+		return callObserver.FinishWithError(session.CompilationError)
+	}
 	if session.CompilerExitCode == 0 {
-		if err := common.TransferFileByChunks(session.OutObjectFilePath, func(chunk []byte) error {
+		sendSpan := span.StartChild("SendObject", common.SpanKindInternal)
+		err := common.TransferFileByChunks(session.OutObjectFilePath, func(chunk []byte) error {
 			if len(chunk) != 0 {
 				return stream.Send(&pb.CompileSourceReply{
 					Chunk: &pb.CompileSourceReply_CompiledObjChunk{
@@ -248,7 +478,10 @@ func (s *CompilationServer) CompileSource(in *pb.CompileSourceRequest, stream pb
 				})
 			}
 			return nil
-		}); err != nil {
+		})
+		sendSpan.SetError(err)
+		sendSpan.End()
+		if err != nil {
 			return callObserver.FinishWithError(fmt.Errorf("Can't send compiled source: %v", err))
 		}
 	}
@@ -259,6 +492,15 @@ func (s *CompilationServer) CompileSource(in *pb.CompileSourceRequest, stream pb
 				CompilerRetCode: int32(session.CompilerExitCode),
 				CompilerStdout:  session.CompilerStdout,
 				CompilerStderr:  session.CompilerStderr,
+
+				ObjectCacheResult: session.ObjectCacheResult,
+				QueueWaitTime:     int64(session.QueueWaitTime),
+				CompileTime:       int64(session.CompileTime),
+				UploadedFiles:     atomic.LoadInt32(&session.UploadedFiles),
+				FilesFromSrcCache: atomic.LoadInt32(&session.FilesFromSrcCache),
+				SystemHeaders:     atomic.LoadInt32(&session.SystemHeaders),
+				ServerName:        s.ServerName,
+				ServerVersion:     common.GetVersion(),
 			},
 		}})
 	return callObserver.Finish()
@@ -270,19 +512,69 @@ func (s *CompilationServer) CloseSession(ctx context.Context, in *pb.CloseSessio
 	if session == nil {
 		return nil, callObserver.FinishWithError(fmt.Errorf("Unknown SessionID %d", in.SessionID))
 	}
-	s.closeSession(session, in.SessionID, true)
+	s.closeSession(session)
 	return &pb.CloseSessionReply{}, callObserver.Finish()
 }
 
-func (s *CompilationServer) Status(ctx context.Context, in *pb.StatusRequest) (*pb.StatusReply, error) {
-	rawOut, _ := exec.Command(in.CheckCompiler, "-v").CombinedOutput()
+// serverCapabilities are the protocol features which clients may rely on, in addition to the configuration dependent ones
+var serverCapabilities = []string{
+	"object-cache-mode",
+	"compilation-priority",
+	"compilation-epilogue-stats",
+	"trace-context",
+	"cache-admin",
+	"drain",
+}
 
-	versionLine := "unknown"
-	for _, line := range strings.Split(string(rawOut), "\n") {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, " version ") {
-			versionLine = line
-			break
+func (s *CompilationServer) getCapabilities() []string {
+	capabilities := append([]string{}, serverCapabilities...)
+	if s.ObjectCachePeers != nil {
+		capabilities = append(capabilities, "peer-object-cache")
+	}
+	if s.Sandbox != nil {
+		capabilities = append(capabilities, "sandbox")
+	}
+	if s.Authenticator != nil {
+		capabilities = append(capabilities, "auth")
+	}
+	return capabilities
+}
+
+// readLoadAverage returns 1, 5 and 15 minutes load average, it is empty if the load is unknown.
+func readLoadAverage() []float64 {
+	data, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return nil
+	}
+	loadAverage := make([]float64, 0, 3)
+	for _, field := range fields[:3] {
+		load, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil
+		}
+		loadAverage = append(loadAverage, load)
+	}
+	return loadAverage
+}
+
+func (s *CompilationServer) Status(ctx context.Context, in *pb.StatusRequest) (*pb.StatusReply, error) {
+	// Only the available compilers are launched for versions, the checked compiler comes from the client
+	versionLine := "not allowed"
+	checkCompilerPath, _ := resolveCompilerPath(in.CheckCompiler)
+	availableCompilers := s.CompilerPolicy.GetAvailableCompilers()
+	compilers := make([]*pb.CompilerInfo, 0, len(availableCompilers))
+	for _, compiler := range availableCompilers {
+		version := s.CompilerIdentities.GetCompilerVersion(compiler)
+		compilers = append(compilers, &pb.CompilerInfo{
+			Compiler: compiler,
+			Version:  version,
+		})
+		if compilerPath, _ := resolveCompilerPath(compiler); compiler == in.CheckCompiler || (len(compilerPath) != 0 && compilerPath == checkCompilerPath) {
+			versionLine = version
 		}
 	}
 
@@ -291,5 +583,18 @@ func (s *CompilationServer) Status(ctx context.Context, in *pb.StatusRequest) (*
 		ServerArgs:      os.Args,
 		ServerUptime:    int64(time.Since(s.StartTime)),
 		CompilerVersion: versionLine,
+
+		RunningCompilations:     s.CompilationQueue.RunningCompilations(),
+		QueuedCompilations:      s.CompilationQueue.QueueLength(),
+		MaxParallelCompilations: s.CompilationQueue.MaxRunningCompilations(),
+		Draining:                s.IsDraining(),
+
+		ServerName:     s.ServerName,
+		CPUCount:       int32(runtime.NumCPU()),
+		LoadAverage:    readLoadAverage(),
+		ActiveSessions: s.ActiveSessions.ActiveSessions(),
+		Caches:         s.makeCacheStats(),
+		Compilers:      compilers,
+		Capabilities:   s.getCapabilities(),
 	}, nil
 }
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+type compilerIdentity struct {
+	mtime  int64
+	size   int64
+	sha256 common.SHA256Struct
+}
+
+type compilerVersion struct {
+	mtime   int64
+	size    int64
+	version string
+}
+
+// CompilerIdentities caches hashes and versions of compiler binaries, they are recalculated if the binary is changed.
+type CompilerIdentities struct {
+	table    map[string]compilerIdentity
+	versions map[string]compilerVersion
+	mu       sync.Mutex
+}
+
+// MakeCompilerIdentities ...
+func MakeCompilerIdentities() *CompilerIdentities {
+	return &CompilerIdentities{
+		table:    make(map[string]compilerIdentity, 16),
+		versions: make(map[string]compilerVersion, 16),
+	}
+}
+
+// statCompiler returns the real path of the compiler binary.
+func statCompiler(compiler string) (string, os.FileInfo, error) {
+	compilerPath, err := resolveCompilerPath(compiler)
+	if err != nil {
+		return "", nil, err
+	}
+	if realPath, err := filepath.EvalSymlinks(compilerPath); err == nil {
+		compilerPath = realPath
+	}
+	stat, err := os.Stat(compilerPath)
+	return compilerPath, stat, err
+}
+
+// GetCompilerIdentity returns the hash of the compiler binary, or the empty hash if the binary is not found.
+func (identities *CompilerIdentities) GetCompilerIdentity(compiler string) common.SHA256Struct {
+	compilerPath, stat, err := statCompiler(compiler)
+	if err != nil {
+		return common.SHA256Struct{}
+	}
+
+	identities.mu.Lock()
+	identity, ok := identities.table[compilerPath]
+	identities.mu.Unlock()
+	if ok && identity.mtime == stat.ModTime().UnixNano() && identity.size == stat.Size() {
+		return identity.sha256
+	}
+
+	identity = compilerIdentity{mtime: stat.ModTime().UnixNano(), size: stat.Size()}
+	if identity.sha256, err = common.GetFileSHA256(compilerPath); err != nil {
+		common.LogWarning("Can't calculate hash of compiler", compilerPath, ":", err)
+		return common.SHA256Struct{}
+	}
+
+	identities.mu.Lock()
+	identities.table[compilerPath] = identity
+	identities.mu.Unlock()
+	return identity.sha256
+}
+
+// GetCompilerVersion returns the version line from the compiler "-v" output, or "unknown" if there is no such line.
+func (identities *CompilerIdentities) GetCompilerVersion(compiler string) string {
+	compilerPath, stat, err := statCompiler(compiler)
+	if err != nil {
+		return "unknown"
+	}
+
+	identities.mu.Lock()
+	version, ok := identities.versions[compilerPath]
+	identities.mu.Unlock()
+	if ok && version.mtime == stat.ModTime().UnixNano() && version.size == stat.Size() {
+		return version.version
+	}
+
+	version = compilerVersion{mtime: stat.ModTime().UnixNano(), size: stat.Size(), version: "unknown"}
+	rawOut, _ := exec.Command(compilerPath, "-v").CombinedOutput()
+	for _, line := range strings.Split(string(rawOut), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, " version ") {
+			version.version = line
+			break
+		}
+	}
+
+	identities.mu.Lock()
+	identities.versions[compilerPath] = version
+	identities.mu.Unlock()
+	return version.version
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// DefaultDeniedCompilerFlags are flags which allow loading code or reading files outside of the session.
+var DefaultDeniedCompilerFlags = []string{
+	"-fplugin", "-fpass-plugin", "-B", "-specs", "--specs", "-wrapper", "-load", "-plugin", "@",
+}
+
+// CompilerPolicy restricts compilers and compiler flags which clients are allowed to use.
+type CompilerPolicy struct {
+	allowedCompilers map[string]bool
+	deniedFlags      []string
+	allowedFlags     []string
+}
+
+func resolveCompilerPath(compiler string) (string, error) {
+	if strings.Contains(compiler, "/") {
+		if !filepath.IsAbs(compiler) {
+			return "", fmt.Errorf("compiler path %q is not absolute", compiler)
+		}
+		return filepath.Clean(compiler), nil
+	}
+	return exec.LookPath(compiler)
+}
+
+// MakeCompilerPolicy ...
+func MakeCompilerPolicy(allowedCompilers []string, deniedFlags []string, allowedFlags []string) *CompilerPolicy {
+	policy := &CompilerPolicy{
+		deniedFlags:  deniedFlags,
+		allowedFlags: allowedFlags,
+	}
+	if len(allowedCompilers) == 0 {
+		common.LogWarning("Compiler allowlist is empty, any executable can be launched by clients")
+		return policy
+	}
+	policy.allowedCompilers = make(map[string]bool, len(allowedCompilers))
+	for _, compiler := range allowedCompilers {
+		compilerPath, err := resolveCompilerPath(compiler)
+		if err != nil {
+			common.LogWarning("Allowed compiler is not found:", err)
+			continue
+		}
+		policy.allowedCompilers[compilerPath] = true
+	}
+	return policy
+}
+
+// CheckCompiler ...
+func (policy *CompilerPolicy) CheckCompiler(compiler string) error {
+	if policy.allowedCompilers == nil {
+		return nil
+	}
+	compilerPath, err := resolveCompilerPath(compiler)
+	if err != nil || !policy.allowedCompilers[compilerPath] {
+		return fmt.Errorf("Compiler %q is not allowed", compiler)
+	}
+	return nil
+}
+
+// wellKnownCompilers are looked up in PATH if the compiler allowlist is empty
+var wellKnownCompilers = []string{"cc", "c++", "gcc", "g++", "clang", "clang++"}
+
+// GetAvailableCompilers returns the allowed compilers, or the well-known compilers found in PATH if any compiler is allowed.
+func (policy *CompilerPolicy) GetAvailableCompilers() []string {
+	var compilers []string
+	if policy.allowedCompilers != nil {
+		for compilerPath := range policy.allowedCompilers {
+			compilers = append(compilers, compilerPath)
+		}
+		sort.Strings(compilers)
+		return compilers
+	}
+	for _, compiler := range wellKnownCompilers {
+		if _, err := exec.LookPath(compiler); err == nil {
+			compilers = append(compilers, compiler)
+		}
+	}
+	return compilers
+}
+
+func hasPrefixFrom(arg string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(arg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompilerArgs ...
+func (policy *CompilerPolicy) CheckCompilerArgs(args []string) error {
+	for _, arg := range args {
+		if hasPrefixFrom(arg, policy.deniedFlags) && !hasPrefixFrom(arg, policy.allowedFlags) {
+			return fmt.Errorf("Compiler flag %q is not allowed", arg)
+		}
+	}
+	return nil
+}
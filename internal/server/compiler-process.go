@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CompilerLimits ...
+type CompilerLimits struct {
+	Timeout time.Duration
+	CPUTime time.Duration
+	Memory  int64
+
+	// CgroupParentDir enables cgroup v2 limits instead of the address space limit
+	CgroupParentDir string
+	CgroupCPUs      float64
+}
+
+func containsAny(data []byte, substrings ...string) bool {
+	for _, substring := range substrings {
+		if bytes.Contains(data, []byte(substring)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *CompilationServer) makeCompilationCgroup(session *ClientSession) *common.Cgroup {
+	if len(s.CompilerLimits.CgroupParentDir) == 0 {
+		return nil
+	}
+	cgroup, err := common.MakeCgroup(s.CompilerLimits.CgroupParentDir, fmt.Sprintf("session-%d", session.SessionID), s.CompilerLimits.Memory, s.CompilerLimits.CgroupCPUs)
+	if err != nil {
+		session.Log.WithPhase("compile").Warning("Can't create cgroup for compilation:", err)
+		return nil
+	}
+	return cgroup
+}
+
+func (s *CompilationServer) runCompiler(session *ClientSession) {
+	limits := &common.ProcessLimits{
+		CPUTime:      s.CompilerLimits.CPUTime,
+		AddressSpace: s.CompilerLimits.Memory,
+		Nice:         CompilerNiceValues[session.Priority],
+	}
+	cgroup := s.makeCompilationCgroup(session)
+	if cgroup != nil {
+		defer cgroup.Remove()
+		limits.CgroupDir = cgroup.Dir
+		limits.AddressSpace = 0
+	}
+
+	compilerProc := common.MakeCommand(s.Sandbox, limits, session.WorkingDir, session.Compiler, session.RemoveUnusedIncludeDirsAndGetCompilerArgs()...)
+	var compilerStderrBuff, compilerStdoutBuff bytes.Buffer
+	compilerProc.Stderr = &compilerStderrBuff
+	compilerProc.Stdout = &compilerStdoutBuff
+
+	session.Log.WithPhase("compile").Info("Launch compiler:", compilerProc.Args)
+	if err := compilerProc.Start(); err != nil {
+		session.CompilerExitCode = -1
+		session.CompilerStderr = []byte(err.Error())
+		return
+	}
+
+	ctx, cancel := session.ctx, context.CancelFunc(func() {})
+	if s.CompilerLimits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.CompilerLimits.Timeout)
+	}
+	compilerExited := make(chan struct{})
+	killerFinished := make(chan struct{})
+	killed := int32(0)
+	go func() {
+		select {
+		case <-ctx.Done():
+			atomic.StoreInt32(&killed, 1)
+			common.KillProcessGroup(compilerProc)
+		case <-compilerExited:
+		}
+		close(killerFinished)
+	}()
+	_ = compilerProc.Wait()
+	close(compilerExited)
+	<-killerFinished
+	cancel()
+
+	session.CompilerExitCode = compilerProc.ProcessState.ExitCode()
+	session.CompilerStdout = compilerStdoutBuff.Bytes()
+	session.CompilerStderr = compilerStderrBuff.Bytes()
+	if session.CompilerExitCode == 0 {
+		return
+	}
+
+	waitStatus, _ := compilerProc.ProcessState.Sys().(syscall.WaitStatus)
+	killedBySignal := waitStatus.Signaled() && (waitStatus.Signal() == syscall.SIGKILL || waitStatus.Signal() == syscall.SIGXCPU)
+	switch {
+	case atomic.LoadInt32(&killed) != 0 && session.IsCancelled():
+		s.Stats.CancelledCompilations.Increment()
+		session.CompilationError = status.Errorf(codes.Canceled, "Compilation of %q is cancelled", session.SourceFilePath)
+	case atomic.LoadInt32(&killed) != 0:
+		s.Stats.CompilationTimeouts.Increment()
+		session.CompilationError = status.Errorf(codes.DeadlineExceeded, "Compilation of %q exceeded %v timeout", session.SourceFilePath, s.CompilerLimits.Timeout)
+	case (cgroup != nil && cgroup.OOMKills() != 0) ||
+		// Under the address space limit the compiler reports the failed allocation,
+		// crashes are not counted, they are usually internal compiler errors
+		(limits.AddressSpace > 0 && containsAny(session.CompilerStderr, "out of memory", "virtual memory exhausted", "Cannot allocate memory")):
+		s.Stats.CompilationMemoryLimitHits.Increment()
+		session.CompilationError = status.Errorf(codes.ResourceExhausted, "Compilation of %q exceeded memory limit", session.SourceFilePath)
+	case limits.CPUTime > 0 && (killedBySignal || containsAny(session.CompilerStderr, "CPU time limit exceeded")):
+		s.Stats.CompilationCPULimitHits.Increment()
+		session.CompilationError = status.Errorf(codes.ResourceExhausted, "Compilation of %q exceeded cpu time limit", session.SourceFilePath)
+	}
+	if session.CompilationError != nil {
+		session.Log.WithPhase("compile").Warning(session.CompilationError)
+	}
+}
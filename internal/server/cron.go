@@ -22,10 +22,19 @@ func (c *Cron) doCron() {
 	for atomic.LoadInt32(&c.stopFlag) == 0 {
 		cronStartTime := time.Now()
 		c.Server.Stats.SendStats(c.Server)
+		if err := c.Server.Tracer.Flush(); err != nil {
+			common.LogWarning("Can't export traces:", err)
+		}
 
 		c.Server.SrcFileCache.PurgeLastElementsIfRequired()
 		c.Server.ObjFileCache.PurgeLastElementsIfRequired()
+		c.Server.SrcFileCache.SaveIndexIfRequired()
+		c.Server.ObjFileCache.SaveIndexIfRequired()
 		c.Server.RemoteClients.PurgeOutdatedClients()
+		c.Server.ReapIdleSessions()
+		if c.Server.StopIfDrained() {
+			break
+		}
 
 		sleepTime := time.Second - time.Since(cronStartTime)
 		if sleepTime <= 0 {
@@ -42,8 +51,7 @@ func (c *Cron) doCron() {
 						common.LogInfo("Log file was rotated")
 					}
 				} else if sig == syscall.SIGTERM {
-					common.LogInfo("Start graceful stop")
-					c.Server.GRPCServer.GracefulStop()
+					c.Server.StartDrain()
 				}
 			case <-time.After(sleepTime):
 				break
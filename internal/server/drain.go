@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
+	"github.com/AlexK0/popcorn/internal/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errDraining is returned to clients which try to start a new session on the draining server.
+var errDraining = status.Error(codes.Unavailable, "Server is draining")
+
+// StartDrain stops accepting new sessions, the server is stopped after the active sessions are finished.
+func (s *CompilationServer) StartDrain() {
+	if !atomic.CompareAndSwapInt32(&s.draining, 0, 1) {
+		return
+	}
+	deadline := int64(0)
+	if s.DrainTimeout > 0 {
+		deadline = time.Now().Add(s.DrainTimeout).UnixNano()
+	}
+	atomic.StoreInt64(&s.drainDeadline, deadline)
+	common.LogInfo("Start draining, active sessions:", s.ActiveSessions.ActiveSessions())
+}
+
+// IsDraining ...
+func (s *CompilationServer) IsDraining() bool {
+	return atomic.LoadInt32(&s.draining) != 0
+}
+
+// StopIfDrained stops the grpc server when the draining is finished or the drain deadline has passed.
+// Returns true if the server has been stopped.
+func (s *CompilationServer) StopIfDrained() bool {
+	if !s.IsDraining() {
+		return false
+	}
+	if activeSessions := s.ActiveSessions.ActiveSessions(); activeSessions != 0 {
+		deadline := atomic.LoadInt64(&s.drainDeadline)
+		if deadline == 0 || time.Now().UnixNano() < deadline {
+			return false
+		}
+		common.LogWarning("Drain deadline has passed, stop with", activeSessions, "active sessions")
+		s.GRPCServer.Stop()
+		return true
+	}
+	common.LogInfo("Server is drained, start graceful stop")
+	s.GRPCServer.GracefulStop()
+	return true
+}
+
+// Drain ...
+func (s *CompilationServer) Drain(ctx context.Context, in *pb.DrainRequest) (*pb.DrainReply, error) {
+	if err := s.checkAdminAccess(); err != nil {
+		return nil, err
+	}
+	s.StartDrain()
+	return &pb.DrainReply{ActiveSessions: s.ActiveSessions.ActiveSessions()}, nil
+}
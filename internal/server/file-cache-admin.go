@@ -0,0 +1,115 @@
+package server
+
+import (
+	"os"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// CacheEntriesFilter selects cache entries, empty fields match any entry.
+type CacheEntriesFilter struct {
+	// FileNamePattern is a shell pattern, see path.Match
+	FileNamePattern string
+	// MinAge selects entries which are not used for this time
+	MinAge time.Duration
+	Key    *common.SHA256Struct
+}
+
+// IsEmpty returns true if the filter matches all entries.
+func (filter *CacheEntriesFilter) IsEmpty() bool {
+	return len(filter.FileNamePattern) == 0 && filter.MinAge <= 0 && filter.Key == nil
+}
+
+func (filter *CacheEntriesFilter) matches(node *lruNode, now int64) bool {
+	if filter.Key != nil && *filter.Key != node.key.key {
+		return false
+	}
+	if filter.MinAge > 0 && now-node.lastAccess < int64(filter.MinAge) {
+		return false
+	}
+	if len(filter.FileNamePattern) != 0 {
+		if matched, _ := path.Match(filter.FileNamePattern, node.key.path); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// CacheEntryInfo ...
+type CacheEntryInfo struct {
+	FileName     string
+	Key          common.SHA256Struct
+	FileSize     int64
+	OriginalSize int64
+	Age          time.Duration
+}
+
+// ListEntries returns up to limit matched entries from the most recently used and the amount of all matched entries.
+func (cache *FileCache) ListEntries(filter CacheEntriesFilter, limit int) ([]CacheEntryInfo, int) {
+	now := time.Now().UnixNano()
+	entries := make([]CacheEntryInfo, 0, 64)
+	matchedEntries := 0
+	cache.mu.Lock()
+	for node := cache.lruHead; node != nil; node = node.next {
+		if !filter.matches(node, now) {
+			continue
+		}
+		matchedEntries++
+		if limit > 0 && len(entries) >= limit {
+			continue
+		}
+		file := cache.table[node.key]
+		entries = append(entries, CacheEntryInfo{
+			FileName:     node.key.path,
+			Key:          node.key.key,
+			FileSize:     file.fileSize,
+			OriginalSize: file.originalSize,
+			Age:          time.Duration(now - node.lastAccess),
+		})
+	}
+	cache.mu.Unlock()
+	return entries, matchedEntries
+}
+
+// EvictEntries removes matched entries, returns the amount of removed files and bytes on disk.
+func (cache *FileCache) EvictEntries(filter CacheEntriesFilter) (int64, int64) {
+	now := time.Now().UnixNano()
+	removingFiles := make([]cachedFile, 0, 64)
+	cache.mu.Lock()
+	for node := cache.lruHead; node != nil; {
+		next := node.next
+		if filter.matches(node, now) {
+			removingFiles = append(removingFiles, cache.table[node.key])
+			cache.unlinkLocked(node)
+		}
+		node = next
+	}
+	cache.mu.Unlock()
+
+	evictedBytes := int64(0)
+	for _, removingFile := range removingFiles {
+		_ = os.Remove(removingFile.pathInCache)
+		atomic.AddInt64(&cache.totalSizeOnDisk, -removingFile.fileSize)
+		atomic.AddInt64(&cache.totalOriginalSize, -removingFile.originalSize)
+		evictedBytes += removingFile.fileSize
+	}
+	atomic.AddInt64(&cache.changes, int64(len(removingFiles)))
+	return int64(len(removingFiles)), evictedBytes
+}
+
+// GetSizeLimit ...
+func (cache *FileCache) GetSizeLimit() int64 {
+	return atomic.LoadInt64(&cache.hardLimit)
+}
+
+// SetSizeLimit changes the cache limit, the least recently used files above the new limit are purged.
+// Returns the previous limit.
+func (cache *FileCache) SetSizeLimit(cacheLimitBytes int64) int64 {
+	previousLimit := atomic.SwapInt64(&cache.hardLimit, cacheLimitBytes)
+	atomic.StoreInt64(&cache.softLimit, makeSoftLimit(cacheLimitBytes))
+	cache.purgeLastElementsTillLimit(cacheLimitBytes)
+	return previousLimit
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/AlexK0/popcorn/internal/common"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressedFileSuffix marks compressed files in the cache
+const compressedFileSuffix = "zst"
+
+var zstdEncoders = sync.Pool{
+	New: func() interface{} {
+		encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return encoder
+	},
+}
+
+// compressFile writes the compressed copy of the source file and returns its size.
+// The copy is renamed to the destination path when it is complete, the cache restores files by their names after crashes.
+func compressFile(srcPath string, destPath string) (int64, error) {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer srcFile.Close()
+
+	// The temp name can't be parsed as a cached file name, such leftovers are removed on the cache loading
+	destFile, err := ioutil.TempFile(path.Dir(destPath), "compressing-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	encoder := zstdEncoders.Get().(*zstd.Encoder)
+	encoder.Reset(destFile)
+	_, err = io.Copy(encoder, srcFile)
+	if closeErr := encoder.Close(); err == nil {
+		err = closeErr
+	}
+	zstdEncoders.Put(encoder)
+
+	var compressedSize int64
+	if err == nil {
+		compressedSize, err = destFile.Seek(0, io.SeekCurrent)
+	}
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(destFile.Name(), destPath)
+	}
+	if err != nil {
+		os.Remove(destFile.Name())
+		return 0, err
+	}
+	return compressedSize, nil
+}
+
+type decompressingFile struct {
+	file    *os.File
+	decoder *zstd.Decoder
+}
+
+func (f *decompressingFile) Read(p []byte) (int, error) {
+	return f.decoder.Read(p)
+}
+
+func (f *decompressingFile) Close() error {
+	// The decoder isn't pooled, it keeps a goroutine until it is closed
+	f.decoder.Close()
+	return f.file.Close()
+}
+
+// openCompressedFile opens the compressed file for reading the original content.
+func openCompressedFile(compressedPath string) (io.ReadCloser, error) {
+	file, err := os.Open(compressedPath)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &decompressingFile{file, decoder}, nil
+}
+
+// getDecompressedSize reads the whole compressed file for its original size.
+func getDecompressedSize(compressedPath string) (int64, error) {
+	reader, err := openCompressedFile(compressedPath)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	return io.Copy(ioutil.Discard, reader)
+}
+
+// decompressFile restores the original content of the compressed file to the destination path.
+func decompressFile(compressedPath string, destPath string) error {
+	reader, err := openCompressedFile(compressedPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	destTmp, err := common.OpenTempFile(destPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destTmp, reader)
+	destTmp.Close()
+	if err == nil {
+		err = os.Rename(destTmp.Name(), destPath)
+	}
+	if err != nil {
+		os.Remove(destTmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+const (
+	fileCacheIndexName    = "index.json"
+	fileCacheIndexVersion = 2
+
+	// fileCacheIndexSaveInterval is the minimal interval between index savings while the server is running
+	fileCacheIndexSaveInterval = time.Minute
+)
+
+type fileCacheIndexEntry struct {
+	FileName    string
+	Key         common.SHA256Struct
+	PathInCache string
+	FileSize    int64
+	// OriginalSize is set for compressed files only
+	OriginalSize int64 `json:",omitempty"`
+	Compressed   bool  `json:",omitempty"`
+	// LastAccess is the unix time in nanoseconds
+	LastAccess int64 `json:",omitempty"`
+}
+
+// fileCacheIndex is the cache table stored on disk, entries go from the most to the least recently used.
+type fileCacheIndex struct {
+	Version       int
+	UniqueCounter uint64
+	Entries       []fileCacheIndexEntry
+}
+
+func (cache *FileCache) indexPath() string {
+	return path.Join(cache.cacheDir, fileCacheIndexName)
+}
+
+// parseUniqueID extracts the unique id from the cached file name "<file name>.<unique id>".
+func parseUniqueID(cachedFileName string) (uint64, bool) {
+	dot := strings.LastIndexByte(cachedFileName, '.')
+	if dot == -1 {
+		return 0, false
+	}
+	uniqueID, err := strconv.ParseUint(cachedFileName[dot+1:], 16, 64)
+	return uniqueID, err == nil
+}
+
+func (cache *FileCache) readIndex() *fileCacheIndex {
+	data, err := ioutil.ReadFile(cache.indexPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			cacheLog.Warning("Can't read cache index:", err)
+		}
+		return nil
+	}
+	index := &fileCacheIndex{}
+	if err = json.Unmarshal(data, index); err != nil {
+		cacheLog.Warning("Can't parse cache index", cache.indexPath(), ":", err)
+		return nil
+	}
+	if index.Version != fileCacheIndexVersion {
+		cacheLog.Warning("Cache index", cache.indexPath(), "has unsupported version", index.Version)
+		return nil
+	}
+	return index
+}
+
+// parseCachedFileName restores the entry fields from the name made by makeCachedFileName.
+func parseCachedFileName(cachedFileName string) (fileName string, key common.SHA256Struct, compressed bool, ok bool) {
+	dot := strings.LastIndexByte(cachedFileName, '.')
+	if dot == -1 {
+		return "", key, false, false
+	}
+	rest := cachedFileName[:dot]
+	if strings.HasSuffix(rest, "."+compressedFileSuffix) {
+		rest = strings.TrimSuffix(rest, "."+compressedFileSuffix)
+		compressed = true
+	}
+	dot = strings.LastIndexByte(rest, '.')
+	if dot <= 0 {
+		return "", key, false, false
+	}
+	key, err := common.MakeSHA256StructFromHex(rest[dot+1:])
+	if err != nil {
+		return "", key, false, false
+	}
+	return rest[:dot], key, compressed, true
+}
+
+// restoreIndexEntry makes the entry of the file which is missing in the index.
+func (cache *FileCache) restoreIndexEntry(pathInCache string, stat os.FileInfo) (fileCacheIndexEntry, bool) {
+	fileName, key, compressed, ok := parseCachedFileName(path.Base(pathInCache))
+	if !ok {
+		return fileCacheIndexEntry{}, false
+	}
+	entry := fileCacheIndexEntry{
+		FileName:    fileName,
+		Key:         key,
+		PathInCache: pathInCache,
+		FileSize:    stat.Size(),
+		LastAccess:  stat.ModTime().UnixNano(),
+		Compressed:  compressed,
+	}
+	if compressed {
+		originalSize, err := getDecompressedSize(path.Join(cache.cacheDir, pathInCache))
+		if err != nil {
+			return fileCacheIndexEntry{}, false
+		}
+		entry.OriginalSize = originalSize
+	}
+	return entry, true
+}
+
+// loadIndex restores the cache table from the index and the files in the shards.
+// Files missing in the index (e.g. after an unclean shutdown, or if the index is corrupted) are restored by their names,
+// files which can't be restored and duplicates are removed.
+func (cache *FileCache) loadIndex() {
+	index := cache.readIndex()
+	if index == nil {
+		index = &fileCacheIndex{}
+	}
+	indexedFiles := make(map[string]*fileCacheIndexEntry, len(index.Entries))
+	for i := len(index.Entries) - 1; i >= 0; i-- {
+		indexedFiles[index.Entries[i].PathInCache] = &index.Entries[i]
+	}
+
+	// The ids of files which are left from the previous run can't be reused
+	uniqueCounter := index.UniqueCounter
+	entries := make([]fileCacheIndexEntry, 0, len(index.Entries))
+	restoredFiles := 0
+	removedFiles := 0
+	loadTime := time.Now().UnixNano()
+	for i := 0; i < DIR_SHARDS; i++ {
+		shard := fmt.Sprintf("%X", i)
+		files, err := ioutil.ReadDir(path.Join(cache.cacheDir, shard))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if uniqueID, ok := parseUniqueID(file.Name()); ok && uniqueID >= uniqueCounter {
+				uniqueCounter = uniqueID + 1
+			}
+			pathInCache := path.Join(shard, file.Name())
+			if file.Mode().IsRegular() {
+				if entry := indexedFiles[pathInCache]; entry != nil && entry.FileSize == file.Size() {
+					entries = append(entries, *entry)
+					continue
+				}
+				if entry, ok := cache.restoreIndexEntry(pathInCache, file); ok {
+					entries = append(entries, entry)
+					restoredFiles++
+					continue
+				}
+			}
+			_ = os.RemoveAll(path.Join(cache.cacheDir, pathInCache))
+			removedFiles++
+		}
+	}
+	cache.uniqueCounter = uniqueCounter
+
+	for i := range entries {
+		if entries[i].LastAccess == 0 {
+			entries[i].LastAccess = loadTime
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastAccess > entries[j].LastAccess
+	})
+	for _, entry := range entries {
+		cachedFilePath := path.Join(cache.cacheDir, entry.PathInCache)
+		cacheKey := CachedFileKey{entry.FileName, entry.Key}
+		if _, exists := cache.table[cacheKey]; exists {
+			_ = os.Remove(cachedFilePath)
+			removedFiles++
+			continue
+		}
+		node := &lruNode{key: cacheKey, prev: cache.lruTail, lastAccess: entry.LastAccess}
+		if cache.lruTail != nil {
+			cache.lruTail.next = node
+		} else {
+			cache.lruHead = node
+		}
+		cache.lruTail = node
+		originalSize := entry.FileSize
+		if entry.Compressed {
+			originalSize = entry.OriginalSize
+		}
+		cache.table[cacheKey] = cachedFile{
+			pathInCache:  cachedFilePath,
+			fileSize:     entry.FileSize,
+			originalSize: originalSize,
+			compressed:   entry.Compressed,
+			lruNode:      node,
+		}
+		cache.totalSizeOnDisk += entry.FileSize
+		cache.totalOriginalSize += originalSize
+	}
+	if restoredFiles != 0 {
+		// The restored entries are saved on the next cron tick
+		cache.changes++
+	}
+
+	cacheLog.Info("Cache", cache.cacheDir, "is loaded:", len(cache.table), "files,", cache.totalSizeOnDisk, "bytes,",
+		restoredFiles, "files restored without index,", removedFiles, "orphan files removed")
+	cache.purgeLastElementsTillLimit(cache.hardLimit)
+}
+
+// SaveIndex writes the cache table to the index file.
+func (cache *FileCache) SaveIndex() error {
+	changes := atomic.LoadInt64(&cache.changes)
+	index := fileCacheIndex{
+		Version:       fileCacheIndexVersion,
+		UniqueCounter: atomic.LoadUint64(&cache.uniqueCounter),
+	}
+
+	cache.mu.Lock()
+	index.Entries = make([]fileCacheIndexEntry, 0, len(cache.table))
+	for node := cache.lruHead; node != nil; node = node.next {
+		file := cache.table[node.key]
+		entry := fileCacheIndexEntry{
+			FileName:    node.key.path,
+			Key:         node.key.key,
+			PathInCache: strings.TrimPrefix(file.pathInCache, cache.cacheDir+"/"),
+			FileSize:    file.fileSize,
+			LastAccess:  node.lastAccess,
+		}
+		if file.compressed {
+			entry.OriginalSize = file.originalSize
+			entry.Compressed = true
+		}
+		index.Entries = append(index.Entries, entry)
+	}
+	cache.mu.Unlock()
+
+	data, err := json.Marshal(&index)
+	if err != nil {
+		return err
+	}
+	if err = common.WriteFile(cache.indexPath(), data); err != nil {
+		return err
+	}
+
+	cache.savedChanges = changes
+	cache.indexSaveTime = time.Now()
+	return nil
+}
+
+// Close ...
+func (cache *FileCache) Close() error {
+	return cache.SaveIndex()
+}
+
+// SaveIndexIfRequired periodically saves the index of the modified cache.
+func (cache *FileCache) SaveIndexIfRequired() {
+	if atomic.LoadInt64(&cache.changes) == cache.savedChanges || time.Since(cache.indexSaveTime) < fileCacheIndexSaveInterval {
+		return
+	}
+	if err := cache.SaveIndex(); err != nil {
+		cacheLog.Error("Can't save cache index:", err)
+	}
+}
@@ -2,33 +2,39 @@ package server
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/AlexK0/popcorn/internal/common"
 )
 
 // CachedFileKey ...
 type CachedFileKey struct {
-	path     string
-	key      common.SHA256Struct
-	extraKey string
+	path string
+	key  common.SHA256Struct
 }
 
 type cachedFile struct {
 	pathInCache string
-	fileSize    int64
-	lruNode     *lruNode
+	// fileSize is the size on disk, originalSize differs from it for compressed files
+	fileSize     int64
+	originalSize int64
+	compressed   bool
+	lruNode      *lruNode
 }
 
 type lruNode struct {
 	next, prev *lruNode
 	key        CachedFileKey
+	// lastAccess is the unix time in nanoseconds of the last use
+	lastAccess int64
 }
 
-// FileCache ...
+// FileCache is the local disk storage, files are hard links in sharded directories.
 type FileCache struct {
 	table            map[CachedFileKey]cachedFile
 	lruTail, lruHead *lruNode
@@ -37,39 +43,60 @@ type FileCache struct {
 	uniqueCounter uint64
 	cacheDir      string
 
-	totalSizeOnDisk int64
-	hardLimit       int64
-	softLimit       int64
+	totalSizeOnDisk   int64
+	totalOriginalSize int64
+	hardLimit         int64
+	softLimit         int64
+
+	// compression enables zstd compression of new files, the limits apply to compressed sizes
+	compression bool
 
 	purgedElements int64
+
+	// changes counts modifications and uses of the table, the index is saved if there are new ones
+	changes       int64
+	savedChanges  int64
+	indexSaveTime time.Time
 }
 
 const DIR_SHARDS = 256
 
-// MakeFileCache ...
-func MakeFileCache(cacheDir string, cacheLimitBytes int64) (*FileCache, error) {
+// MakeFileCache creates the cache and loads files which are left from the previous run.
+func MakeFileCache(cacheDir string, cacheLimitBytes int64, compression bool) (*FileCache, error) {
 	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
 		return nil, err
 	}
 	for i := 0; i < DIR_SHARDS; i++ {
 		dir := path.Join(cacheDir, fmt.Sprintf("%X", i))
-		if err := os.Mkdir(dir, os.ModePerm); err != nil {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			return nil, err
 		}
 	}
-	return &FileCache{
-		table:     make(map[CachedFileKey]cachedFile, 128*1024),
-		cacheDir:  cacheDir,
-		hardLimit: cacheLimitBytes,
-		softLimit: int64(80.0 * (float64(cacheLimitBytes) / 100.0)),
-	}, nil
+	cache := &FileCache{
+		table:         make(map[CachedFileKey]cachedFile, 128*1024),
+		cacheDir:      path.Clean(cacheDir),
+		hardLimit:     cacheLimitBytes,
+		softLimit:     makeSoftLimit(cacheLimitBytes),
+		compression:   compression,
+		indexSaveTime: time.Now(),
+	}
+	cache.loadIndex()
+	return cache, nil
 }
 
-// CreateLinkFromCache ...
-func (cache *FileCache) CreateLinkFromCacheExtra(destPath string, key common.SHA256Struct, extraKey string) bool {
-	cacheKey := CachedFileKey{path.Base(destPath), key, extraKey}
+func makeSoftLimit(hardLimit int64) int64 {
+	return int64(80.0 * (float64(hardLimit) / 100.0))
+}
+
+// lookup returns the cached file and marks it as the most recently used.
+func (cache *FileCache) lookup(cacheKey CachedFileKey) cachedFile {
 	cache.mu.Lock()
 	cachedFile := cache.table[cacheKey]
+	if cachedFile.lruNode != nil {
+		cachedFile.lruNode.lastAccess = time.Now().UnixNano()
+		// The recency order is a part of the index
+		atomic.AddInt64(&cache.changes, 1)
+	}
 	if cachedFile.lruNode != nil && cachedFile.lruNode != cache.lruHead {
 		// cachedFile.lruNode != cache.lruHead => cachedFile.lruNode.prev != nil
 		cachedFile.lruNode.prev.next = cachedFile.lruNode.next
@@ -87,38 +114,139 @@ func (cache *FileCache) CreateLinkFromCacheExtra(destPath string, key common.SHA
 		cache.lruHead = cachedFile.lruNode
 	}
 	cache.mu.Unlock()
+	return cachedFile
+}
 
+// CreateLinkFromCache ...
+func (cache *FileCache) CreateLinkFromCache(destPath string, key common.SHA256Struct) bool {
+	cachedFile := cache.lookup(CachedFileKey{path.Base(destPath), key})
 	if cachedFile.lruNode == nil {
 		return false
 	}
 	if err := os.MkdirAll(path.Dir(destPath), os.ModePerm); err != nil {
 		return false
 	}
+	if cachedFile.compressed {
+		if err := decompressFile(cachedFile.pathInCache, destPath); err != nil {
+			cacheLog.Warning("Can't decompress cached file:", err)
+			return false
+		}
+		return true
+	}
 	return os.Link(cachedFile.pathInCache, destPath) == nil
 }
 
-func (cache *FileCache) CreateLinkFromCache(destPath string, fileSHA256key common.SHA256Struct) bool {
-	return cache.CreateLinkFromCacheExtra(destPath, fileSHA256key, "")
+// unlinkLocked removes the node from the lru list and the table, the file on disk is kept.
+func (cache *FileCache) unlinkLocked(node *lruNode) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		cache.lruHead = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		cache.lruTail = node.prev
+	}
+	delete(cache.table, node.key)
+}
+
+// RemoveFromCache ...
+func (cache *FileCache) RemoveFromCache(fileName string, key common.SHA256Struct) bool {
+	cacheKey := CachedFileKey{fileName, key}
+	cache.mu.Lock()
+	removingFile, exists := cache.table[cacheKey]
+	if exists {
+		cache.unlinkLocked(removingFile.lruNode)
+	}
+	cache.mu.Unlock()
+
+	if exists {
+		atomic.AddInt64(&cache.changes, 1)
+		_ = os.Remove(removingFile.pathInCache)
+		atomic.AddInt64(&cache.totalSizeOnDisk, -removingFile.fileSize)
+		atomic.AddInt64(&cache.totalOriginalSize, -removingFile.originalSize)
+	}
+	return exists
+}
+
+// OpenCachedFile ...
+func (cache *FileCache) OpenCachedFile(fileName string, key common.SHA256Struct) io.ReadCloser {
+	cachedFile := cache.lookup(CachedFileKey{fileName, key})
+	if cachedFile.lruNode == nil {
+		return nil
+	}
+	// The file may be purged right after the lookup
+	if cachedFile.compressed {
+		file, err := openCompressedFile(cachedFile.pathInCache)
+		if err != nil {
+			return nil
+		}
+		return file
+	}
+	file, err := os.Open(cachedFile.pathInCache)
+	if err != nil {
+		return nil
+	}
+	return file
+}
+
+// makeCachedFileName returns "<shard>/<file name>.<key>[.zst].<unique id>",
+// the name keeps everything required for restoring the entry without the index.
+func makeCachedFileName(fileName string, key common.SHA256Struct, uniqueID uint64, compressed bool) string {
+	compressedSuffix := ""
+	if compressed {
+		compressedSuffix = "." + compressedFileSuffix
+	}
+	return fmt.Sprintf("%X/%s.%s%s.%X", uniqueID%DIR_SHARDS, fileName, key.ToHex(), compressedSuffix, uniqueID)
 }
 
 // SaveFileToCache ...
-func (cache *FileCache) SaveFileToCacheExtra(srcPath string, key common.SHA256Struct, extraKey string, fileSize int64) (bool, error) {
-	uniqueID := atomic.AddUint64(&cache.uniqueCounter, 1) - 1
+func (cache *FileCache) SaveFileToCache(srcPath string, key common.SHA256Struct, fileSize int64) (bool, error) {
 	fileName := path.Base(srcPath)
-	cachedFileName := fmt.Sprintf("%X/%s.%X", uniqueID%DIR_SHARDS, fileName, uniqueID)
-	cachedFilePath := path.Join(cache.cacheDir, cachedFileName)
+	cacheKey := CachedFileKey{fileName, key}
+	// Most of the transferred files are cached already, they aren't compressed again
+	cache.mu.Lock()
+	_, exists := cache.table[cacheKey]
+	cache.mu.Unlock()
+	if exists {
+		return false, nil
+	}
+
+	uniqueID := atomic.AddUint64(&cache.uniqueCounter, 1) - 1
 
-	if err := os.Link(srcPath, cachedFilePath); err != nil {
-		return false, err
+	value := cachedFile{fileSize: fileSize, originalSize: fileSize}
+	if cache.compression {
+		compressedFilePath := path.Join(cache.cacheDir, makeCachedFileName(fileName, key, uniqueID, true))
+		compressedSize, err := compressFile(srcPath, compressedFilePath)
+		if err != nil {
+			return false, err
+		}
+		// Incompressible files are kept as is
+		if compressedSize < fileSize {
+			value.pathInCache = compressedFilePath
+			value.fileSize = compressedSize
+			value.compressed = true
+		} else {
+			_ = os.Remove(compressedFilePath)
+		}
+	}
+	if !value.compressed {
+		value.pathInCache = path.Join(cache.cacheDir, makeCachedFileName(fileName, key, uniqueID, false))
+		if err := os.Link(srcPath, value.pathInCache); err != nil {
+			return false, err
+		}
 	}
 
-	cacheKey := CachedFileKey{fileName, key, extraKey}
-	newHead := &lruNode{key: cacheKey}
-	value := cachedFile{pathInCache: cachedFilePath, fileSize: fileSize, lruNode: newHead}
+	newHead := &lruNode{key: cacheKey, lastAccess: time.Now().UnixNano()}
+	value.lruNode = newHead
 	cache.mu.Lock()
-	_, exists := cache.table[cacheKey]
+	// The file may be saved concurrently
+	_, exists = cache.table[cacheKey]
 	if !exists {
-		atomic.AddInt64(&cache.totalSizeOnDisk, fileSize)
+		atomic.AddInt64(&cache.changes, 1)
+		atomic.AddInt64(&cache.totalSizeOnDisk, value.fileSize)
+		atomic.AddInt64(&cache.totalOriginalSize, value.originalSize)
 		cache.table[cacheKey] = value
 		newHead.next = cache.lruHead
 		if cache.lruHead != nil {
@@ -132,20 +260,16 @@ func (cache *FileCache) SaveFileToCacheExtra(srcPath string, key common.SHA256St
 	cache.mu.Unlock()
 
 	if exists {
-		_ = os.Remove(cachedFilePath)
+		_ = os.Remove(value.pathInCache)
 	}
 
-	cache.purgeLastElementsTillLimit(cache.hardLimit)
+	cache.purgeLastElementsTillLimit(atomic.LoadInt64(&cache.hardLimit))
 	return !exists, nil
 }
 
-func (cache *FileCache) SaveFileToCache(srcPath string, fileSHA256 common.SHA256Struct, fileSize int64) (bool, error) {
-	return cache.SaveFileToCacheExtra(srcPath, fileSHA256, "", fileSize)
-}
-
 // PurgeLastElementsIfRequired ...
 func (cache *FileCache) PurgeLastElementsIfRequired() {
-	cache.purgeLastElementsTillLimit(cache.softLimit)
+	cache.purgeLastElementsTillLimit(atomic.LoadInt64(&cache.softLimit))
 }
 
 // GetFilesCount ...
@@ -161,6 +285,16 @@ func (cache *FileCache) GetBytesOnDisk() int64 {
 	return atomic.LoadInt64(&cache.totalSizeOnDisk)
 }
 
+// GetOriginalBytes returns the size of cached files before compression.
+func (cache *FileCache) GetOriginalBytes() int64 {
+	return atomic.LoadInt64(&cache.totalOriginalSize)
+}
+
+// IsRemote ...
+func (cache *FileCache) IsRemote() bool {
+	return false
+}
+
 // GetPurgedFiles ...
 func (cache *FileCache) GetPurgedFiles() int64 {
 	return atomic.LoadInt64(&cache.purgedElements)
@@ -172,15 +306,21 @@ func (cache *FileCache) purgeLastElementsTillLimit(cacheLimit int64) {
 		cache.mu.Lock()
 		if tail := cache.lruTail; tail != nil {
 			cache.lruTail = tail.prev
-			cache.lruTail.next = nil
+			if cache.lruTail != nil {
+				cache.lruTail.next = nil
+			} else {
+				cache.lruHead = nil
+			}
 			removingFile = cache.table[tail.key]
 			delete(cache.table, tail.key)
 		}
 		cache.mu.Unlock()
 
 		if removingFile.lruNode != nil {
+			atomic.AddInt64(&cache.changes, 1)
 			_ = os.Remove(removingFile.pathInCache)
 			atomic.AddInt64(&cache.totalSizeOnDisk, -removingFile.fileSize)
+			atomic.AddInt64(&cache.totalOriginalSize, -removingFile.originalSize)
 			atomic.AddInt64(&cache.purgedElements, 1)
 		}
 	}
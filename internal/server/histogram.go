@@ -0,0 +1,104 @@
+package server
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type histogramUnit int
+
+const (
+	// histogramSeconds observes durations in nanoseconds, the zero Histogram has this unit
+	histogramSeconds histogramUnit = iota
+	histogramBytes
+)
+
+// histogramMaxPendingSamples limits the samples sent to statsd per flush, the rest is accounted by the sample rate.
+// The samples are a uniform random sample of the flush interval (reservoir sampling), so the rate is valid for percentiles.
+const histogramMaxPendingSamples = 256
+
+var histogramDurationBounds = []int64{
+	int64(time.Millisecond), int64(2500 * time.Microsecond), int64(5 * time.Millisecond),
+	int64(10 * time.Millisecond), int64(25 * time.Millisecond), int64(50 * time.Millisecond),
+	int64(100 * time.Millisecond), int64(250 * time.Millisecond), int64(500 * time.Millisecond),
+	int64(time.Second), int64(2500 * time.Millisecond), int64(5 * time.Second),
+	int64(10 * time.Second), int64(30 * time.Second), int64(time.Minute),
+	int64(2 * time.Minute), int64(5 * time.Minute), int64(10 * time.Minute),
+}
+
+var histogramSizeBounds = []int64{
+	1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10,
+	1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20,
+}
+
+// Histogram counts observations in fixed buckets and keeps recent samples for statsd timers.
+type Histogram struct {
+	unit histogramUnit
+
+	// counts are not cumulative, the last one is for values above all bounds
+	counts [20]int64
+	count  int64
+	sum    int64
+
+	mu              sync.Mutex
+	pending         []int64
+	pendingObserved int64
+}
+
+func (h *Histogram) bounds() []int64 {
+	if h.unit == histogramBytes {
+		return histogramSizeBounds
+	}
+	return histogramDurationBounds
+}
+
+// Observe adds the value in nanoseconds or bytes.
+func (h *Histogram) Observe(value int64) {
+	bounds := h.bounds()
+	bucket := len(bounds)
+	for i, bound := range bounds {
+		if value <= bound {
+			bucket = i
+			break
+		}
+	}
+	atomic.AddInt64(&h.counts[bucket], 1)
+	atomic.AddInt64(&h.count, 1)
+	atomic.AddInt64(&h.sum, value)
+
+	h.mu.Lock()
+	h.pendingObserved++
+	if len(h.pending) < histogramMaxPendingSamples {
+		h.pending = append(h.pending, value)
+	} else if i := rand.Int63n(h.pendingObserved); i < histogramMaxPendingSamples {
+		h.pending[i] = value
+	}
+	h.mu.Unlock()
+}
+
+// ObserveDuration ...
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(int64(d))
+}
+
+// takePendingSamples returns the samples since the previous call and the fraction of kept observations.
+func (h *Histogram) takePendingSamples() ([]int64, float64) {
+	h.mu.Lock()
+	samples, observed := h.pending, h.pendingObserved
+	h.pending, h.pendingObserved = nil, 0
+	h.mu.Unlock()
+	if observed == 0 {
+		return nil, 1
+	}
+	return samples, float64(len(samples)) / float64(observed)
+}
+
+// scale converts raw values to seconds or bytes.
+func (h *Histogram) scale(value int64) float64 {
+	if h.unit == histogramBytes {
+		return float64(value)
+	}
+	return time.Duration(value).Seconds()
+}
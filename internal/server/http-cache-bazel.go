@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of build.bazel.remote.execution.v2 messages, bazel-remote accepts only ActionResult messages in the action cache.
+const (
+	bazelActionResultOutputFiles protowire.Number = 2
+	bazelOutputFilePath          protowire.Number = 1
+	bazelOutputFileDigest        protowire.Number = 2
+	bazelDigestHash              protowire.Number = 1
+	bazelDigestSizeBytes         protowire.Number = 2
+)
+
+// bazelDigest refers a blob in the content addressable storage.
+type bazelDigest struct {
+	Hash      string
+	SizeBytes int64
+}
+
+// makeBazelActionResult encodes the ActionResult with the single output file.
+func makeBazelActionResult(fileName string, digest bazelDigest) []byte {
+	var digestMessage []byte
+	digestMessage = protowire.AppendTag(digestMessage, bazelDigestHash, protowire.BytesType)
+	digestMessage = protowire.AppendString(digestMessage, digest.Hash)
+	digestMessage = protowire.AppendTag(digestMessage, bazelDigestSizeBytes, protowire.VarintType)
+	digestMessage = protowire.AppendVarint(digestMessage, uint64(digest.SizeBytes))
+
+	var outputFile []byte
+	outputFile = protowire.AppendTag(outputFile, bazelOutputFilePath, protowire.BytesType)
+	outputFile = protowire.AppendString(outputFile, fileName)
+	outputFile = protowire.AppendTag(outputFile, bazelOutputFileDigest, protowire.BytesType)
+	outputFile = protowire.AppendBytes(outputFile, digestMessage)
+
+	var actionResult []byte
+	actionResult = protowire.AppendTag(actionResult, bazelActionResultOutputFiles, protowire.BytesType)
+	return protowire.AppendBytes(actionResult, outputFile)
+}
+
+// findProtoBytesField returns the first length delimited field with the number.
+func findProtoBytesField(message []byte, number protowire.Number) ([]byte, bool, error) {
+	for len(message) != 0 {
+		fieldNumber, fieldType, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return nil, false, protowire.ParseError(n)
+		}
+		message = message[n:]
+		if fieldNumber == number && fieldType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(message)
+			if n < 0 {
+				return nil, false, protowire.ParseError(n)
+			}
+			return value, true, nil
+		}
+		if n = protowire.ConsumeFieldValue(fieldNumber, fieldType, message); n < 0 {
+			return nil, false, protowire.ParseError(n)
+		}
+		message = message[n:]
+	}
+	return nil, false, nil
+}
+
+// parseBazelActionResult returns the digest of the first output file.
+func parseBazelActionResult(actionResult []byte) (bazelDigest, error) {
+	outputFile, found, err := findProtoBytesField(actionResult, bazelActionResultOutputFiles)
+	if err != nil || !found {
+		return bazelDigest{}, fmt.Errorf("Action result has no output files: %v", err)
+	}
+	digestMessage, found, err := findProtoBytesField(outputFile, bazelOutputFileDigest)
+	if err != nil || !found {
+		return bazelDigest{}, fmt.Errorf("Output file has no digest: %v", err)
+	}
+
+	digest := bazelDigest{}
+	for len(digestMessage) != 0 {
+		fieldNumber, fieldType, n := protowire.ConsumeTag(digestMessage)
+		if n < 0 {
+			return bazelDigest{}, protowire.ParseError(n)
+		}
+		digestMessage = digestMessage[n:]
+		switch {
+		case fieldNumber == bazelDigestHash && fieldType == protowire.BytesType:
+			digest.Hash, n = protowire.ConsumeString(digestMessage)
+		case fieldNumber == bazelDigestSizeBytes && fieldType == protowire.VarintType:
+			var sizeBytes uint64
+			sizeBytes, n = protowire.ConsumeVarint(digestMessage)
+			digest.SizeBytes = int64(sizeBytes)
+		default:
+			n = protowire.ConsumeFieldValue(fieldNumber, fieldType, digestMessage)
+		}
+		if n < 0 {
+			return bazelDigest{}, protowire.ParseError(n)
+		}
+		digestMessage = digestMessage[n:]
+	}
+	if len(digest.Hash) != 64 {
+		return bazelDigest{}, fmt.Errorf("Bad output file digest %q", digest.Hash)
+	}
+	return digest, nil
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// HTTP cache layouts
+const (
+	// HTTPCacheLayoutFlat stores files as <url>/<key>
+	HTTPCacheLayoutFlat = "flat"
+	// HTTPCacheLayoutSubdirs stores files as <url>/<first 2 key chars>/<rest of key>, like ccache http storage
+	HTTPCacheLayoutSubdirs = "subdirs"
+	// HTTPCacheLayoutBazel stores content addressed files as <url>/cas/<key>, like bazel-remote.
+	// Other files are stored as <url>/cas/<sha256 of content>, the ActionResult in <url>/ac/<key> refers them.
+	HTTPCacheLayoutBazel = "bazel"
+)
+
+// bazelMaxActionResultSize limits the downloaded action results, they have one output file
+const bazelMaxActionResultSize = 64 * 1024
+
+// errHTTPCacheMiss is returned for files which are not in the http cache
+var errHTTPCacheMiss = errors.New("File is not found in http cache")
+
+// HTTPCacheStorage keeps files in a remote cache service with GET/PUT http api, the service manages the eviction.
+type HTTPCacheStorage struct {
+	baseURL string
+	layout  string
+	// contentAddressed is set if the keys are hashes of the file contents
+	contentAddressed bool
+	client           *http.Client
+
+	Hits     AtomicStat
+	Misses   AtomicStat
+	Errors   AtomicStat
+	Uploaded AtomicStat
+}
+
+// MakeHTTPCacheStorage ...
+func MakeHTTPCacheStorage(baseURL string, layout string, contentAddressed bool, timeout time.Duration) (*HTTPCacheStorage, error) {
+	switch layout {
+	case HTTPCacheLayoutFlat, HTTPCacheLayoutSubdirs, HTTPCacheLayoutBazel:
+	default:
+		return nil, fmt.Errorf("Unknown http cache layout %q", layout)
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("Http cache url %q must start with http:// or https://", baseURL)
+	}
+	return &HTTPCacheStorage{
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		layout:           layout,
+		contentAddressed: contentAddressed,
+		client:           &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (storage *HTTPCacheStorage) makeURL(key common.SHA256Struct) string {
+	hexKey := key.ToHex()
+	switch storage.layout {
+	case HTTPCacheLayoutSubdirs:
+		return storage.baseURL + "/" + hexKey[:2] + "/" + hexKey[2:]
+	case HTTPCacheLayoutBazel:
+		if storage.contentAddressed {
+			return storage.baseURL + "/cas/" + hexKey
+		}
+		return storage.baseURL + "/ac/" + hexKey
+	}
+	return storage.baseURL + "/" + hexKey
+}
+
+// usesActionCache is true if the files are referred by the bazel action results.
+func (storage *HTTPCacheStorage) usesActionCache() bool {
+	return storage.layout == HTTPCacheLayoutBazel && !storage.contentAddressed
+}
+
+// fetch returns the body of the successful response.
+func (storage *HTTPCacheStorage) fetch(url string) (io.ReadCloser, error) {
+	response, err := storage.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusOK {
+		return response.Body, nil
+	}
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errHTTPCacheMiss
+	}
+	return nil, fmt.Errorf("Unexpected http cache response: %s", response.Status)
+}
+
+// verifyingReader fails at the end of the content if it doesn't match the digest,
+// so a broken or hostile cache service can't substitute files.
+type verifyingReader struct {
+	io.ReadCloser
+	hasher hash.Hash
+	size   int64
+	// digest.SizeBytes is negative if the size is unknown
+	digest bazelDigest
+}
+
+func makeVerifyingReader(body io.ReadCloser, digest bazelDigest) *verifyingReader {
+	return &verifyingReader{ReadCloser: body, hasher: sha256.New(), digest: digest}
+}
+
+func (reader *verifyingReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	_, _ = reader.hasher.Write(p[:n])
+	reader.size += int64(n)
+	if err == io.EOF {
+		if hash := hex.EncodeToString(reader.hasher.Sum(nil)); !strings.EqualFold(hash, reader.digest.Hash) {
+			return n, fmt.Errorf("Http cache file has sha256 %s, expected %s", hash, reader.digest.Hash)
+		}
+		if reader.digest.SizeBytes >= 0 && reader.size != reader.digest.SizeBytes {
+			return n, fmt.Errorf("Http cache file has %d bytes, expected %d", reader.size, reader.digest.SizeBytes)
+		}
+	}
+	return n, err
+}
+
+// fetchFile returns the file body, it is verified while reading if the content hash is known.
+func (storage *HTTPCacheStorage) fetchFile(key common.SHA256Struct) (io.ReadCloser, error) {
+	body, err := storage.fetch(storage.makeURL(key))
+	if err != nil {
+		return nil, err
+	}
+	if !storage.usesActionCache() {
+		if storage.contentAddressed {
+			return makeVerifyingReader(body, bazelDigest{Hash: key.ToHex(), SizeBytes: -1}), nil
+		}
+		// The key of other files is a digest of the compilation inputs, there is nothing to verify
+		return body, nil
+	}
+	actionResult, err := ioutil.ReadAll(io.LimitReader(body, bazelMaxActionResultSize))
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := parseBazelActionResult(actionResult)
+	if err != nil {
+		return nil, err
+	}
+	if body, err = storage.fetch(storage.baseURL + "/cas/" + digest.Hash); err != nil {
+		return nil, err
+	}
+	return makeVerifyingReader(body, digest), nil
+}
+
+func (storage *HTTPCacheStorage) get(key common.SHA256Struct) io.ReadCloser {
+	body, err := storage.fetchFile(key)
+	switch {
+	case err == nil:
+		storage.Hits.Increment()
+		return body
+	case err == errHTTPCacheMiss:
+		storage.Misses.Increment()
+	default:
+		storage.Errors.Increment()
+		cacheLog.Warning("Can't get file from http cache:", err)
+	}
+	return nil
+}
+
+// CreateLinkFromCache downloads the file, the name is the part of the key in the local cache only.
+func (storage *HTTPCacheStorage) CreateLinkFromCache(destPath string, key common.SHA256Struct) bool {
+	body := storage.get(key)
+	if body == nil {
+		return false
+	}
+	defer body.Close()
+
+	fileTmp, err := common.OpenTempFile(destPath)
+	if err != nil {
+		return false
+	}
+	_, err = io.Copy(fileTmp, body)
+	fileTmp.Close()
+	if err == nil {
+		err = os.Rename(fileTmp.Name(), destPath)
+	}
+	if err != nil {
+		storage.Errors.Increment()
+		cacheLog.Warning("Can't download file from http cache:", err)
+		os.Remove(fileTmp.Name())
+		return false
+	}
+	return true
+}
+
+func (storage *HTTPCacheStorage) upload(url string, body io.Reader, size int64) error {
+	request, err := http.NewRequest(http.MethodPut, url, body)
+	if err != nil {
+		return err
+	}
+	request.ContentLength = size
+	request.Header.Set("Content-Type", "application/octet-stream")
+	response, err := storage.client.Do(request)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s", response.Status)
+	}
+	return nil
+}
+
+func (storage *HTTPCacheStorage) uploadFile(url string, srcPath string, fileSize int64) error {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return storage.upload(url, file, fileSize)
+}
+
+// uploadWithActionResult uploads the file to the content addressable storage and the action result which refers it.
+func (storage *HTTPCacheStorage) uploadWithActionResult(srcPath string, key common.SHA256Struct, fileSize int64) error {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	digest := bazelDigest{Hash: hex.EncodeToString(hasher.Sum(nil)), SizeBytes: fileSize}
+	if err = storage.uploadFile(storage.baseURL+"/cas/"+digest.Hash, srcPath, fileSize); err != nil {
+		return err
+	}
+	actionResult := makeBazelActionResult(path.Base(srcPath), digest)
+	return storage.upload(storage.makeURL(key), bytes.NewReader(actionResult), int64(len(actionResult)))
+}
+
+// SaveFileToCache uploads the file.
+func (storage *HTTPCacheStorage) SaveFileToCache(srcPath string, key common.SHA256Struct, fileSize int64) (bool, error) {
+	var err error
+	if storage.usesActionCache() {
+		err = storage.uploadWithActionResult(srcPath, key, fileSize)
+	} else {
+		err = storage.uploadFile(storage.makeURL(key), srcPath, fileSize)
+	}
+	if err != nil {
+		storage.Errors.Increment()
+		return false, fmt.Errorf("Can't upload %q to http cache: %v", path.Base(srcPath), err)
+	}
+	storage.Uploaded.Increment()
+	return true, nil
+}
+
+// RemoveFromCache does nothing, the cache services don't support removing, but the upload replaces the file.
+func (storage *HTTPCacheStorage) RemoveFromCache(fileName string, key common.SHA256Struct) bool {
+	return false
+}
+
+// OpenCachedFile ...
+func (storage *HTTPCacheStorage) OpenCachedFile(fileName string, key common.SHA256Struct) io.ReadCloser {
+	return storage.get(key)
+}
+
+// PurgeLastElementsIfRequired does nothing, the cache service evicts files itself.
+func (storage *HTTPCacheStorage) PurgeLastElementsIfRequired() {
+}
+
+// SaveIndexIfRequired does nothing, there is no local state.
+func (storage *HTTPCacheStorage) SaveIndexIfRequired() {
+}
+
+// Close ...
+func (storage *HTTPCacheStorage) Close() error {
+	storage.client.CloseIdleConnections()
+	return nil
+}
+
+// GetFilesCount returns the amount of uploaded files, the size of the remote cache is unknown.
+func (storage *HTTPCacheStorage) GetFilesCount() int64 {
+	return storage.Uploaded.Get()
+}
+
+// GetBytesOnDisk ...
+func (storage *HTTPCacheStorage) GetBytesOnDisk() int64 {
+	return 0
+}
+
+// IsRemote ...
+func (storage *HTTPCacheStorage) IsRemote() bool {
+	return true
+}
+
+// GetPurgedFiles ...
+func (storage *HTTPCacheStorage) GetPurgedFiles() int64 {
+	return 0
+}
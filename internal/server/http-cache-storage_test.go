@@ -0,0 +1,231 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// fakeHTTPCache is an in-memory GET/PUT cache service, paths listed in failingPaths respond with 500.
+type fakeHTTPCache struct {
+	mu           sync.Mutex
+	files        map[string][]byte
+	failingPaths map[string]bool
+}
+
+func (cache *fakeHTTPCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.failingPaths[r.URL.Path] {
+		http.Error(w, "failure", http.StatusInternalServerError)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := cache.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cache.files[r.URL.Path] = data
+	default:
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+	}
+}
+
+func startFakeHTTPCache(t *testing.T) (*fakeHTTPCache, *httptest.Server) {
+	fakeCache := &fakeHTTPCache{files: make(map[string][]byte), failingPaths: make(map[string]bool)}
+	httpServer := httptest.NewServer(fakeCache)
+	t.Cleanup(httpServer.Close)
+	return fakeCache, httpServer
+}
+
+func makeTestHTTPCacheStorage(t *testing.T, baseURL string, layout string, contentAddressed bool) *HTTPCacheStorage {
+	storage, err := MakeHTTPCacheStorage(baseURL+"/cache", layout, contentAddressed, time.Second)
+	if err != nil {
+		t.Fatalf("Can't make http cache storage: %v", err)
+	}
+	t.Cleanup(func() { _ = storage.Close() })
+	return storage
+}
+
+func writeTestFile(t *testing.T, dir string, name string, content string) string {
+	filePath := path.Join(dir, name)
+	if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Can't write test file: %v", err)
+	}
+	return filePath
+}
+
+var testHTTPCacheKey = common.SHA256Struct{B0_7: 0xab01, B8_15: 2, B16_23: 3, B24_31: 4}
+
+func TestHTTPCacheStorageLayouts(t *testing.T) {
+	const content = "compiled object"
+	contentHash := sha256.Sum256([]byte(content))
+	contentHex := hex.EncodeToString(contentHash[:])
+	keyHex := testHTTPCacheKey.ToHex()
+
+	tests := []struct {
+		layout           string
+		contentAddressed bool
+		// expectedPaths are the uploaded paths, the action result isn't compared byte by byte
+		expectedPaths []string
+	}{
+		{HTTPCacheLayoutFlat, false, []string{"/cache/" + keyHex}},
+		{HTTPCacheLayoutSubdirs, false, []string{"/cache/" + keyHex[:2] + "/" + keyHex[2:]}},
+		{HTTPCacheLayoutBazel, true, []string{"/cache/cas/" + contentHex}},
+		{HTTPCacheLayoutBazel, false, []string{"/cache/ac/" + keyHex, "/cache/cas/" + contentHex}},
+	}
+	for _, test := range tests {
+		fakeCache, httpServer := startFakeHTTPCache(t)
+		storage := makeTestHTTPCacheStorage(t, httpServer.URL, test.layout, test.contentAddressed)
+		dir := t.TempDir()
+		srcPath := writeTestFile(t, dir, "m.o", content)
+		key := testHTTPCacheKey
+		if test.contentAddressed {
+			key = common.MakeSHA256StructFromSlice(contentHash[:])
+		}
+
+		saved, err := storage.SaveFileToCache(srcPath, key, int64(len(content)))
+		if err != nil || !saved {
+			t.Fatalf("%s: SaveFileToCache() = %v, %v", test.layout, saved, err)
+		}
+		if len(fakeCache.files) != len(test.expectedPaths) {
+			t.Errorf("%s: uploaded %d files, expected %d", test.layout, len(fakeCache.files), len(test.expectedPaths))
+		}
+		for _, expectedPath := range test.expectedPaths {
+			if _, ok := fakeCache.files[expectedPath]; !ok {
+				t.Errorf("%s: %s isn't uploaded", test.layout, expectedPath)
+			}
+		}
+		if test.layout == HTTPCacheLayoutBazel && !test.contentAddressed {
+			digest, err := parseBazelActionResult(fakeCache.files["/cache/ac/"+keyHex])
+			if err != nil {
+				t.Fatalf("Bad action result: %v", err)
+			}
+			if digest.Hash != contentHex || digest.SizeBytes != int64(len(content)) {
+				t.Errorf("Action result refers %+v, expected %s/%d", digest, contentHex, len(content))
+			}
+		}
+
+		destPath := path.Join(dir, "out", "m.o")
+		if err = os.MkdirAll(path.Dir(destPath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if !storage.CreateLinkFromCache(destPath, key) {
+			t.Fatalf("%s: cached file isn't found", test.layout)
+		}
+		if data, err := ioutil.ReadFile(destPath); err != nil || string(data) != content {
+			t.Errorf("%s: downloaded %q, %v, expected %q", test.layout, data, err, content)
+		}
+		if storage.CreateLinkFromCache(path.Join(dir, "out", "miss.o"), common.SHA256Struct{B0_7: 1}) {
+			t.Errorf("%s: missing file is found", test.layout)
+		}
+		if storage.Hits.Get() != 1 || storage.Misses.Get() != 1 || storage.Errors.Get() != 0 || storage.Uploaded.Get() != 1 {
+			t.Errorf("%s: hits %d, misses %d, errors %d, uploaded %d, expected 1, 1, 0, 1", test.layout,
+				storage.Hits.Get(), storage.Misses.Get(), storage.Errors.Get(), storage.Uploaded.Get())
+		}
+	}
+}
+
+func TestHTTPCacheStorageErrors(t *testing.T) {
+	keyHex := testHTTPCacheKey.ToHex()
+	fakeCache, httpServer := startFakeHTTPCache(t)
+	storage := makeTestHTTPCacheStorage(t, httpServer.URL, HTTPCacheLayoutFlat, true)
+	fakeCache.failingPaths["/cache/"+keyHex] = true
+	dir := t.TempDir()
+	srcPath := writeTestFile(t, dir, "m.o", "object")
+
+	if saved, err := storage.SaveFileToCache(srcPath, testHTTPCacheKey, 6); err == nil || saved {
+		t.Errorf("SaveFileToCache() = %v, %v, expected error", saved, err)
+	}
+	if storage.CreateLinkFromCache(path.Join(dir, "out.o"), testHTTPCacheKey) {
+		t.Errorf("File is found on server error")
+	}
+	if storage.Errors.Get() != 2 || storage.Hits.Get() != 0 || storage.Misses.Get() != 0 || storage.Uploaded.Get() != 0 {
+		t.Errorf("Errors %d, hits %d, misses %d, uploaded %d, expected 2, 0, 0, 0",
+			storage.Errors.Get(), storage.Hits.Get(), storage.Misses.Get(), storage.Uploaded.Get())
+	}
+}
+
+func TestHTTPCacheStorageBazelMissingBlob(t *testing.T) {
+	fakeCache, httpServer := startFakeHTTPCache(t)
+	storage := makeTestHTTPCacheStorage(t, httpServer.URL, HTTPCacheLayoutBazel, false)
+	dir := t.TempDir()
+	srcPath := writeTestFile(t, dir, "m.o", "object")
+	if _, err := storage.SaveFileToCache(srcPath, testHTTPCacheKey, 6); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache service may evict the blob, the action result is still there
+	for filePath := range fakeCache.files {
+		if path.Dir(filePath) == "/cache/cas" {
+			delete(fakeCache.files, filePath)
+		}
+	}
+	if storage.CreateLinkFromCache(path.Join(dir, "out.o"), testHTTPCacheKey) {
+		t.Errorf("File with evicted blob is found")
+	}
+	if storage.Misses.Get() != 1 {
+		t.Errorf("Misses %d, expected 1", storage.Misses.Get())
+	}
+}
+
+func TestHTTPCacheStorageCorruptedFiles(t *testing.T) {
+	const content = "header"
+	contentHash := sha256.Sum256([]byte(content))
+	contentKey := common.MakeSHA256StructFromSlice(contentHash[:])
+
+	tests := []struct {
+		layout           string
+		contentAddressed bool
+		key              common.SHA256Struct
+	}{
+		{HTTPCacheLayoutFlat, true, contentKey},
+		{HTTPCacheLayoutSubdirs, true, contentKey},
+		{HTTPCacheLayoutBazel, true, contentKey},
+		{HTTPCacheLayoutBazel, false, testHTTPCacheKey},
+	}
+	for _, test := range tests {
+		fakeCache, httpServer := startFakeHTTPCache(t)
+		storage := makeTestHTTPCacheStorage(t, httpServer.URL, test.layout, test.contentAddressed)
+		dir := t.TempDir()
+		srcPath := writeTestFile(t, dir, "inc.h", content)
+		if _, err := storage.SaveFileToCache(srcPath, test.key, int64(len(content))); err != nil {
+			t.Fatal(err)
+		}
+
+		// The cache service returns other content for the same digest
+		for filePath := range fakeCache.files {
+			if test.contentAddressed || path.Dir(filePath) == "/cache/cas" {
+				fakeCache.files[filePath] = []byte("int evil();")
+			}
+		}
+		destPath := path.Join(dir, "out.h")
+		if storage.CreateLinkFromCache(destPath, test.key) {
+			t.Errorf("%s: corrupted file is accepted", test.layout)
+		}
+		if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+			t.Errorf("%s: corrupted file is saved", test.layout)
+		}
+		if storage.Errors.Get() != 1 {
+			t.Errorf("%s: errors %d, expected 1", test.layout, storage.Errors.Get())
+		}
+	}
+}
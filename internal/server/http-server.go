@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+// HTTPServerOptions enables the optional endpoints of the monitoring http server.
+type HTTPServerOptions struct {
+	// StatusPage serves the status page on / and its json equivalent on /status.json
+	StatusPage bool
+	// Pprof serves the Go profiler on /debug/pprof/
+	Pprof bool
+}
+
+// isLoopbackAddress is true if the <host:port> address listens only on the loopback interface.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// StartHTTPServer serves the monitoring endpoints in background.
+// The http server has neither TLS nor authentication, so the status page and pprof are served only on the loopback address.
+func StartHTTPServer(address string, compilationServer *CompilationServer, options HTTPServerOptions) *http.Server {
+	if (options.StatusPage || options.Pprof) && !isLoopbackAddress(address) {
+		common.LogWarning("Status page and pprof are disabled on the non loopback address", address)
+		options.StatusPage = false
+		options.Pprof = false
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", compilationServer.ServeMetrics)
+	if options.StatusPage {
+		mux.HandleFunc("/", compilationServer.ServeStatusPage)
+		mux.HandleFunc("/status.json", compilationServer.ServeStatusJSON)
+	}
+	if options.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	httpServer := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			common.LogError("Failed to serve http:", err)
+		}
+	}()
+	return httpServer
+}
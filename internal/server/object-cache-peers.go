@@ -0,0 +1,398 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
+	"github.com/AlexK0/popcorn/internal/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// peerVirtualNodes is the amount of points of each peer on the hash ring, more points give more even distribution.
+const peerVirtualNodes = 64
+
+type peerRingNode struct {
+	hash uint64
+	peer string
+}
+
+// MakePeerDialOptions makes options for connecting to peers, TLS is enabled if the CA is set.
+// The client certificate is used if the peers require it.
+func MakePeerDialOptions(caFile string, certFile string, keyFile string, tokenFile string) ([]grpc.DialOption, error) {
+	dialOptions := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(common.ZstdCompressorName)),
+	}
+	useTLS := len(caFile) != 0
+	if useTLS {
+		caPEM, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read peer CA: %v", err)
+		}
+		tlsConfig := &tls.Config{RootCAs: x509.NewCertPool(), MinVersion: tls.VersionTLS12}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("No certificates found in peer CA %q", caFile)
+		}
+		if len(certFile) != 0 {
+			certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("Can't load peer client certificate: %v", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+	if len(tokenFile) != 0 {
+		if !useTLS {
+			return nil, fmt.Errorf("Peer auth token can't be sent without TLS, the peer CA is required")
+		}
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read peer auth token: %v", err)
+		}
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(common.MakeBearerTokenCredentials(strings.TrimSpace(string(token)))))
+	}
+	return dialOptions, nil
+}
+
+// peersLog is the logger of the object cache peers
+var peersLog = common.MakeLogger("peers")
+
+// peerMaxPushes limits the objects which are pushed to their owners in background, new pushes are dropped above it
+const peerMaxPushes = 8
+
+// ObjectCachePeers finds objects missing in the local cache on other servers of the cluster.
+// Each object key is owned by one peer, chosen by consistent hashing, only the owner is asked.
+// Objects compiled on a server which doesn't own the key are pushed to the owner.
+type ObjectCachePeers struct {
+	self    string
+	ring    []peerRingNode
+	timeout time.Duration
+
+	pushSlots chan struct{}
+
+	dialOptions []grpc.DialOption
+	connections map[string]*grpc.ClientConn
+	mu          sync.Mutex
+
+	Hits      AtomicStat
+	Misses    AtomicStat
+	Errors    AtomicStat
+	FetchTime AtomicStat
+
+	Pushes        AtomicStat
+	PushErrors    AtomicStat
+	PushesDropped AtomicStat
+}
+
+func peerRingHash(data string) uint64 {
+	hash := sha256.Sum256([]byte(data))
+	return binary.BigEndian.Uint64(hash[:8])
+}
+
+// MakeObjectCachePeers ...
+func MakeObjectCachePeers(peers []string, self string, timeout time.Duration, dialOptions []grpc.DialOption) *ObjectCachePeers {
+	cachePeers := &ObjectCachePeers{
+		self:        self,
+		ring:        make([]peerRingNode, 0, len(peers)*peerVirtualNodes),
+		timeout:     timeout,
+		pushSlots:   make(chan struct{}, peerMaxPushes),
+		dialOptions: dialOptions,
+		connections: make(map[string]*grpc.ClientConn, len(peers)),
+	}
+	for _, peer := range peers {
+		for i := 0; i < peerVirtualNodes; i++ {
+			cachePeers.ring = append(cachePeers.ring, peerRingNode{peerRingHash(fmt.Sprintf("%s#%d", peer, i)), peer})
+		}
+	}
+	sort.Slice(cachePeers.ring, func(i, j int) bool {
+		return cachePeers.ring[i].hash < cachePeers.ring[j].hash
+	})
+	return cachePeers
+}
+
+// GetOwner returns the peer which owns the key.
+func (cachePeers *ObjectCachePeers) GetOwner(key common.SHA256Struct) string {
+	if len(cachePeers.ring) == 0 {
+		return ""
+	}
+	// The key is a sha256 digest already
+	index := sort.Search(len(cachePeers.ring), func(i int) bool {
+		return cachePeers.ring[i].hash >= key.B0_7
+	})
+	if index == len(cachePeers.ring) {
+		index = 0
+	}
+	return cachePeers.ring[index].peer
+}
+
+func (cachePeers *ObjectCachePeers) getConnection(peer string) (*grpc.ClientConn, error) {
+	cachePeers.mu.Lock()
+	defer cachePeers.mu.Unlock()
+	if cachePeers.connections == nil {
+		return nil, fmt.Errorf("Peers are closed")
+	}
+	if connection := cachePeers.connections[peer]; connection != nil {
+		return connection, nil
+	}
+	connection, err := grpc.Dial(peer, cachePeers.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	cachePeers.connections[peer] = connection
+	return connection, nil
+}
+
+// FetchObject asks the owner of the key for the object and saves it to the destination path.
+func (cachePeers *ObjectCachePeers) FetchObject(destPath string, key common.SHA256Struct) bool {
+	owner := cachePeers.GetOwner(key)
+	if len(owner) == 0 || owner == cachePeers.self {
+		return false
+	}
+
+	start := time.Now()
+	defer func() { cachePeers.FetchTime.AddDuration(time.Since(start)) }()
+	err := cachePeers.fetchObject(owner, destPath, key)
+	if err == nil {
+		cachePeers.Hits.Increment()
+		return true
+	}
+	if status.Code(err) == codes.NotFound {
+		cachePeers.Misses.Increment()
+	} else {
+		cachePeers.Errors.Increment()
+		peersLog.Warning("Can't fetch object from peer", owner, ":", err)
+	}
+	return false
+}
+
+func (cachePeers *ObjectCachePeers) fetchObject(peer string, destPath string, key common.SHA256Struct) error {
+	connection, err := cachePeers.getConnection(peer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cachePeers.timeout)
+	defer cancel()
+	stream, err := pb.NewCompilationServiceClient(connection).GetCachedObject(ctx, &pb.GetCachedObjectRequest{
+		Key:      common.SHA256StructToSHA256Message(key),
+		FileName: path.Base(destPath),
+	})
+	if err != nil {
+		return err
+	}
+
+	objTmp, err := common.OpenTempFile(destPath)
+	if err != nil {
+		return err
+	}
+	for {
+		var reply *pb.GetCachedObjectReply
+		if reply, err = stream.Recv(); err != nil {
+			break
+		}
+		if _, err = objTmp.Write(reply.ObjChunk); err != nil {
+			break
+		}
+	}
+	objTmp.Close()
+	if err == io.EOF {
+		err = os.Rename(objTmp.Name(), destPath)
+	}
+	if err != nil {
+		os.Remove(objTmp.Name())
+	}
+	return err
+}
+
+// PushObject sends the object from the local cache to the owner of the key in background.
+func (cachePeers *ObjectCachePeers) PushObject(cache CacheStorage, fileName string, key common.SHA256Struct) {
+	owner := cachePeers.GetOwner(key)
+	if len(owner) == 0 || owner == cachePeers.self {
+		return
+	}
+	select {
+	case cachePeers.pushSlots <- struct{}{}:
+	default:
+		cachePeers.PushesDropped.Increment()
+		return
+	}
+	go func() {
+		defer func() { <-cachePeers.pushSlots }()
+		if err := cachePeers.pushObject(owner, cache, fileName, key); err != nil {
+			cachePeers.PushErrors.Increment()
+			peersLog.Warning("Can't push object to peer", owner, ":", err)
+		} else {
+			cachePeers.Pushes.Increment()
+		}
+	}()
+}
+
+func (cachePeers *ObjectCachePeers) pushObject(peer string, cache CacheStorage, fileName string, key common.SHA256Struct) error {
+	cachedFile := cache.OpenCachedFile(fileName, key)
+	if cachedFile == nil {
+		return fmt.Errorf("Object %q is purged from the local cache", fileName)
+	}
+	defer cachedFile.Close()
+
+	connection, err := cachePeers.getConnection(peer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cachePeers.timeout)
+	defer cancel()
+	stream, err := pb.NewCompilationServiceClient(connection).PutCachedObject(ctx)
+	if err != nil {
+		return err
+	}
+	err = stream.Send(&pb.PutCachedObjectRequest{
+		Chunk: &pb.PutCachedObjectRequest_Header{
+			Header: &pb.PutCachedObjectRequest_StreamHeader{
+				Key:      common.SHA256StructToSHA256Message(key),
+				FileName: fileName,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var buffer [64 * 1024]byte
+	for {
+		n, err := cachedFile.Read(buffer[:])
+		if n != 0 {
+			if sendErr := stream.Send(&pb.PutCachedObjectRequest{Chunk: &pb.PutCachedObjectRequest_ObjChunk{ObjChunk: buffer[:n]}}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Can't read cached object: %v", err)
+		}
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// Close ...
+func (cachePeers *ObjectCachePeers) Close() {
+	cachePeers.mu.Lock()
+	for _, connection := range cachePeers.connections {
+		connection.Close()
+	}
+	cachePeers.connections = nil
+	cachePeers.mu.Unlock()
+}
+
+// GetCachedObject sends the object from the local cache to the peer.
+func (s *CompilationServer) GetCachedObject(in *pb.GetCachedObjectRequest, stream pb.CompilationService_GetCachedObjectServer) error {
+	callObserver := s.Stats.GetCachedObject.StartRPCCall()
+	if err := s.checkPeerAccess(); err != nil {
+		return callObserver.FinishWithError(err)
+	}
+	if in.Key == nil {
+		return callObserver.FinishWithError(status.Error(codes.InvalidArgument, "Object key is required"))
+	}
+
+	cachedFile := s.ObjFileCache.OpenCachedFile(path.Base(in.FileName), common.SHA256MessageToSHA256Struct(in.Key))
+	if cachedFile == nil {
+		_ = callObserver.Finish()
+		return status.Error(codes.NotFound, "Object is not cached")
+	}
+	defer cachedFile.Close()
+
+	var buffer [64 * 1024]byte
+	for {
+		n, err := cachedFile.Read(buffer[:])
+		if n != 0 {
+			if sendErr := stream.Send(&pb.GetCachedObjectReply{ObjChunk: buffer[:n]}); sendErr != nil {
+				return callObserver.FinishWithError(sendErr)
+			}
+		}
+		if err == io.EOF {
+			return callObserver.Finish()
+		}
+		if err != nil {
+			return callObserver.FinishWithError(fmt.Errorf("Can't read cached object: %v", err))
+		}
+	}
+}
+
+// PutCachedObject saves the object pushed by the peer to the local cache.
+func (s *CompilationServer) PutCachedObject(stream pb.CompilationService_PutCachedObjectServer) error {
+	callObserver := s.Stats.PutCachedObject.StartRPCCall()
+	if err := s.checkPeerAccess(); err != nil {
+		return callObserver.FinishWithError(err)
+	}
+	request, err := stream.Recv()
+	if err != nil {
+		return callObserver.FinishWithError(err)
+	}
+	header := request.GetHeader()
+	if header == nil || header.Key == nil {
+		return callObserver.FinishWithError(status.Error(codes.InvalidArgument, "Object header is required"))
+	}
+	fileName := path.Base(header.FileName)
+	if fileName == "." || fileName == "/" || fileName == ".." {
+		return callObserver.FinishWithError(status.Errorf(codes.InvalidArgument, "Bad object file name %q", header.FileName))
+	}
+
+	// The cache takes the file name from the path
+	if err = os.MkdirAll(s.SessionsDir, os.ModePerm); err != nil {
+		return callObserver.FinishWithError(fmt.Errorf("Can't create sessions dir: %v", err))
+	}
+	objDir, err := ioutil.TempDir(s.SessionsDir, "peer-object-")
+	if err != nil {
+		return callObserver.FinishWithError(fmt.Errorf("Can't create temp dir for object: %v", err))
+	}
+	defer os.RemoveAll(objDir)
+	objPath := path.Join(objDir, fileName)
+	objFile, err := os.Create(objPath)
+	if err != nil {
+		return callObserver.FinishWithError(fmt.Errorf("Can't create object file: %v", err))
+	}
+	objSize := int64(0)
+	for {
+		if request, err = stream.Recv(); err != nil {
+			break
+		}
+		chunk := request.GetObjChunk()
+		if _, err = objFile.Write(chunk); err != nil {
+			break
+		}
+		objSize += int64(len(chunk))
+	}
+	objFile.Close()
+	if err != io.EOF {
+		return callObserver.FinishWithError(fmt.Errorf("Can't receive object: %v", err))
+	}
+
+	saved, err := s.ObjFileCache.SaveFileToCache(objPath, common.SHA256MessageToSHA256Struct(header.Key), objSize)
+	if err != nil {
+		return callObserver.FinishWithError(fmt.Errorf("Can't save object to cache: %v", err))
+	}
+	if err = stream.SendAndClose(&pb.PutCachedObjectReply{Saved: saved}); err != nil {
+		return callObserver.FinishWithError(err)
+	}
+	return callObserver.Finish()
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+type prometheusFamily struct {
+	name    string
+	kind    metricKind
+	samples []string
+}
+
+// prometheusSink formats metrics in the Prometheus text format, the samples are grouped by metric families.
+type prometheusSink struct {
+	families      []*prometheusFamily
+	familiesIndex map[string]*prometheusFamily
+}
+
+var prometheusNameReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+func prometheusName(statName string, kind metricKind) string {
+	name := "popcorn_" + prometheusNameReplacer.Replace(statName)
+	if kind == metricCounter {
+		name += "_total"
+	}
+	return name
+}
+
+func (sink *prometheusSink) getFamily(name string, kind metricKind) *prometheusFamily {
+	family := sink.familiesIndex[name]
+	if family == nil {
+		family = &prometheusFamily{name: name, kind: kind}
+		sink.families = append(sink.families, family)
+		sink.familiesIndex[name] = family
+	}
+	return family
+}
+
+func (sink *prometheusSink) addSample(name string, kind metricKind, labels string, value string) {
+	family := sink.getFamily(name, kind)
+	family.samples = append(family.samples, name+labels+" "+value)
+}
+
+func formatPrometheusFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// addHistogram adds cumulative buckets, the sum and the count, the labels are without braces.
+func (sink *prometheusSink) addHistogram(name string, labels string, histogram *Histogram) {
+	family := sink.getFamily(name, metricHistogram)
+	labelsPrefix := ""
+	if len(labels) != 0 {
+		labelsPrefix = labels + ","
+	}
+	cumulativeCount := int64(0)
+	bounds := histogram.bounds()
+	for i, bound := range bounds {
+		cumulativeCount += atomic.LoadInt64(&histogram.counts[i])
+		family.samples = append(family.samples, fmt.Sprintf(`%s_bucket{%sle="%s"} %d`,
+			name, labelsPrefix, formatPrometheusFloat(histogram.scale(bound)), cumulativeCount))
+	}
+	cumulativeCount += atomic.LoadInt64(&histogram.counts[len(bounds)])
+	family.samples = append(family.samples, fmt.Sprintf(`%s_bucket{%sle="+Inf"} %d`, name, labelsPrefix, cumulativeCount))
+	if len(labels) != 0 {
+		labels = "{" + labels + "}"
+	}
+	family.samples = append(family.samples,
+		name+"_sum"+labels+" "+formatPrometheusFloat(histogram.scale(atomic.LoadInt64(&histogram.sum))),
+		name+"_count"+labels+" "+strconv.FormatInt(atomic.LoadInt64(&histogram.count), 10))
+}
+
+func (sink *prometheusSink) writeStat(statName string, kind metricKind, value int64) {
+	sink.addSample(prometheusName(statName, kind), kind, "", strconv.FormatInt(value, 10))
+}
+
+func (sink *prometheusSink) writeFloatStat(statName string, kind metricKind, value float64) {
+	sink.addSample(prometheusName(statName, kind), kind, "", formatPrometheusFloat(value))
+}
+
+func (sink *prometheusSink) writeHistogram(statName string, histogram *Histogram) {
+	name := prometheusName(statName, metricGauge) + "_seconds"
+	if histogram.unit == histogramBytes {
+		name = prometheusName(statName, metricGauge) + "_bytes"
+	}
+	sink.addHistogram(name, "", histogram)
+}
+
+func (sink *prometheusSink) writeRPCCallStat(rpcCallName string, statValue *RPCCallStats) {
+	labels := `{rpc="` + rpcCallName + `"}`
+	sink.addSample("popcorn_rpc_calls_total", metricCounter, labels, strconv.FormatInt(statValue.Calls.Get(), 10))
+	sink.addSample("popcorn_rpc_errors_total", metricCounter, labels, strconv.FormatInt(statValue.Errors.Get(), 10))
+	sink.addSample("popcorn_rpc_processing_time_seconds_total", metricCounter, labels,
+		formatPrometheusFloat(statValue.ProcessingTime.GetAsSeconds()))
+	sink.addHistogram("popcorn_rpc_latency_seconds", `rpc="`+rpcCallName+`"`, &statValue.Latency)
+}
+
+func (sink *prometheusSink) writeTo(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	for _, family := range sink.families {
+		metricType := "gauge"
+		switch family.kind {
+		case metricCounter:
+			metricType = "counter"
+		case metricHistogram:
+			metricType = "histogram"
+		}
+		out.WriteString("# TYPE " + family.name + " " + metricType + "\n")
+		for _, sample := range family.samples {
+			out.WriteString(sample + "\n")
+		}
+	}
+	return out.Flush()
+}
+
+// ServeMetrics handles Prometheus scrapes, the metrics are the same as sent to statsd.
+func (s *CompilationServer) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	sink := &prometheusSink{familiesIndex: make(map[string]*prometheusFamily, 128)}
+	s.Stats.collectStats(sink, s)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = sink.writeTo(w)
+}
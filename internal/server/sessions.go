@@ -1,11 +1,17 @@
 package server
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/AlexK0/popcorn/internal/common"
 
@@ -19,52 +25,265 @@ type requiredFileMetadata struct {
 	relPathInWorkingDir string
 }
 
+// Session states
+const (
+	SessionStateUploading int32 = iota
+	SessionStateQueued
+	SessionStateCompiling
+	SessionStateCompiled
+)
+
 type ClientSession struct {
 	clientUserDir string
 	compilerArgs  []string
 
+	// ctx is cancelled when the session is closed or the client is gone
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lastActivity int64
+	state        int32
+
+	// mu orders the compilation start, CompileSource and the abandon timer against the session closing,
+	// the client, the reaper and the abandon timer may close the session concurrently
+	mu           sync.Mutex
+	closed       bool
+	activeCalls  int32
+	abandonTimer *time.Timer
+
+	SessionID         uint64
+	CreatedAt         time.Time
+	ClientUserName    string
+	SourceFilePath    string
 	OutObjectFilePath string
 	Compiler          string
 	WorkingDir        string
 	UseObjectCache    bool
+	ReadObjectCache   bool
+	WriteObjectCache  bool
+	// OverwriteObjectCache replaces the cached object by the fresh one
+	OverwriteObjectCache bool
+	Priority             pb.CompilationPriority
 
 	ClientInfo        *Client
 	RequiredFilesMeta []requiredFileMetadata
 
 	CompilationStartDependencies int32
-	CompilationWaitFinish        sync.WaitGroup
+	// CompilationWaitFinish is added under mu, so it can't be added after the closing waits for it
+	CompilationWaitFinish sync.WaitGroup
+	CompilationStarted    bool
+	CompileSourceCalled   bool
 
 	CompilerExitCode int
 	CompilerStdout   []byte
 	CompilerStderr   []byte
+	CompilationError error
+
+	// Required files by the way they get into the session, updated atomically
+	UploadedFiles     int32
+	FilesFromSrcCache int32
+	SystemHeaders     int32
+
+	ObjectCacheResult pb.ObjectCacheResult
+	QueueWaitTime     time.Duration
+	CompileTime       time.Duration
+
+	// TraceContext is the span of the session start, the compilation phases are its children
+	TraceContext common.SpanContext
+
+	// Log adds the session id, the client user and the source to records
+	Log *common.Logger
+}
+
+// Cancel stops the compilation of the session, the running compiler is killed.
+func (session *ClientSession) Cancel() {
+	session.cancel()
+}
+
+// IsCancelled ...
+func (session *ClientSession) IsCancelled() bool {
+	return session.ctx.Err() != nil
+}
+
+// enterCall registers the rpc which works with the session, false if the session is closed.
+// The session files are removed after the last registered call leaves the closed session.
+func (session *ClientSession) enterCall() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.closed {
+		return false
+	}
+	session.activeCalls++
+	return true
+}
+
+// leaveCall unregisters the rpc, true if the session is closed and the caller must clean it up.
+func (session *ClientSession) leaveCall() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.activeCalls--
+	return session.closed && session.activeCalls == 0
+}
+
+// markClosed cancels the session and refuses new calls and the compilation start,
+// true if the caller must clean the session up, i.e. it is closed right now and there are no calls.
+func (session *ClientSession) markClosed() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.markClosedLocked()
+}
+
+// markClosedIfAbandoned closes the session if CompileSource isn't called yet, the cleanup is like in markClosed.
+func (session *ClientSession) markClosedIfAbandoned() (abandoned bool, cleanup bool) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.CompileSourceCalled || session.closed {
+		return false, false
+	}
+	return true, session.markClosedLocked()
+}
+
+func (session *ClientSession) markClosedLocked() bool {
+	if session.closed {
+		return false
+	}
+	session.closed = true
+	session.Cancel()
+	if session.abandonTimer != nil {
+		session.abandonTimer.Stop()
+	}
+	return session.activeCalls == 0
+}
+
+// startCompilation adds the compilation to CompilationWaitFinish, false if the session is closed.
+func (session *ClientSession) startCompilation() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.closed {
+		return false
+	}
+	session.CompilationWaitFinish.Add(1)
+	session.CompilationStarted = true
+	return true
+}
+
+// hasActiveCalls ...
+func (session *ClientSession) hasActiveCalls() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.activeCalls != 0
+}
+
+// isCompilationStarted ...
+func (session *ClientSession) isCompilationStarted() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.CompilationStarted
+}
+
+// startAbandonTimer calls abandon after the timeout, if CompileSource isn't called before.
+func (session *ClientSession) startAbandonTimer(timeout time.Duration, abandon func()) {
+	session.mu.Lock()
+	if !session.CompileSourceCalled && !session.closed {
+		session.abandonTimer = time.AfterFunc(timeout, abandon)
+	}
+	session.mu.Unlock()
 }
 
-func (session *ClientSession) MakeObjectCacheKey() (common.SHA256Struct, string) {
-	sha256xor := common.SHA256Struct{}
+// startCompileSource marks the session as requested by CompileSource and stops the abandon timer,
+// false if the session is already closed.
+func (session *ClientSession) startCompileSource() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.closed || session.IsCancelled() {
+		return false
+	}
+	session.CompileSourceCalled = true
+	if session.abandonTimer != nil {
+		session.abandonTimer.Stop()
+	}
+	return true
+}
+
+func (session *ClientSession) setObjectCacheMode(mode pb.ObjectCacheMode, useObjectCache bool) {
+	if mode == pb.ObjectCacheMode_OBJ_CACHE_DEFAULT {
+		mode = pb.ObjectCacheMode_OBJ_CACHE_OFF
+		if useObjectCache {
+			mode = pb.ObjectCacheMode_OBJ_CACHE_READ_WRITE
+		}
+	}
+	session.UseObjectCache = mode != pb.ObjectCacheMode_OBJ_CACHE_OFF
+	session.ReadObjectCache = mode == pb.ObjectCacheMode_OBJ_CACHE_READ_WRITE || mode == pb.ObjectCacheMode_OBJ_CACHE_READ_ONLY
+	session.WriteObjectCache = mode == pb.ObjectCacheMode_OBJ_CACHE_READ_WRITE || mode == pb.ObjectCacheMode_OBJ_CACHE_WRITE_ONLY ||
+		mode == pb.ObjectCacheMode_OBJ_CACHE_RECACHE
+	session.OverwriteObjectCache = mode == pb.ObjectCacheMode_OBJ_CACHE_RECACHE
+}
 
-	keyBuilder := strings.Builder{}
-	keyBuilder.Grow(1024)
+// Touch marks the session as used by the client right now.
+func (session *ClientSession) Touch() {
+	atomic.StoreInt64(&session.lastActivity, time.Now().UnixNano())
+}
+
+// IdleTime returns the time since the last client activity.
+func (session *ClientSession) IdleTime() time.Duration {
+	return time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&session.lastActivity))
+}
+
+// SetState ...
+func (session *ClientSession) SetState(state int32) {
+	atomic.StoreInt32(&session.state, state)
+}
+
+// State ...
+func (session *ClientSession) State() int32 {
+	return atomic.LoadInt32(&session.state)
+}
+
+// objectCacheKeyVersion is a part of the object cache key, it must be changed with the key format.
+const objectCacheKeyVersion = "popcorn-obj-key-v1"
+
+// MakeObjectCacheKey returns a digest of the compiler, the compiler args and the required files with their paths.
+// Each field is prefixed with its length, so the fields can't be shifted into each other.
+func (session *ClientSession) MakeObjectCacheKey(compilerIdentity common.SHA256Struct) common.SHA256Struct {
+	hasher := sha256.New()
+	var buffer [8]byte
+	writeUint64 := func(value uint64) {
+		binary.BigEndian.PutUint64(buffer[:], value)
+		_, _ = hasher.Write(buffer[:])
+	}
+	writeString := func(value string) {
+		writeUint64(uint64(len(value)))
+		_, _ = hasher.Write([]byte(value))
+	}
+	writeSHA256 := func(value common.SHA256Struct) {
+		writeUint64(value.B0_7)
+		writeUint64(value.B8_15)
+		writeUint64(value.B16_23)
+		writeUint64(value.B24_31)
+	}
 
-	keyBuilder.WriteString("compiler-")
-	keyBuilder.WriteString(session.Compiler)
-	keyBuilder.WriteString(";args-")
+	writeString(objectCacheKeyVersion)
+	writeString(session.Compiler)
+	writeSHA256(compilerIdentity)
 
+	writeUint64(uint64(len(session.compilerArgs)))
 	for _, arg := range session.compilerArgs {
-		keyBuilder.WriteString(arg)
-		keyBuilder.WriteString(" ")
+		writeString(arg)
 	}
 
-	keyBuilder.WriteString(";depends-")
-	for _, requiredFile := range session.RequiredFilesMeta {
-		keyBuilder.WriteString("f:")
-		keyBuilder.WriteString(requiredFile.relPathInWorkingDir)
-		fmt.Fprintf(&keyBuilder, "{0x%X/0x%X/0x%X/0x%X}", requiredFile.B0_7, requiredFile.B8_15, requiredFile.B16_23, requiredFile.B24_31)
-		sha256xor.B0_7 ^= requiredFile.B0_7
-		sha256xor.B8_15 ^= requiredFile.B8_15
-		sha256xor.B16_23 ^= requiredFile.B16_23
-		sha256xor.B24_31 ^= requiredFile.B24_31
+	requiredFiles := make([]*requiredFileMetadata, 0, len(session.RequiredFilesMeta))
+	for i := range session.RequiredFilesMeta {
+		requiredFiles = append(requiredFiles, &session.RequiredFilesMeta[i])
 	}
-	return sha256xor, keyBuilder.String()
+	sort.Slice(requiredFiles, func(i, j int) bool {
+		return requiredFiles[i].relPathInWorkingDir < requiredFiles[j].relPathInWorkingDir
+	})
+	writeUint64(uint64(len(requiredFiles)))
+	for _, requiredFile := range requiredFiles {
+		writeString(requiredFile.relPathInWorkingDir)
+		writeSHA256(requiredFile.SHA256Struct)
+	}
+	return common.MakeSHA256StructFromSlice(hasher.Sum(nil))
 }
 
 func (session *ClientSession) getPathInWorkingDir(filePathOnClientFileSystem string) (relative string, absolute string) {
@@ -110,21 +329,39 @@ func MakeSessions() *Sessions {
 	}
 }
 
-func (s *Sessions) OpenNewSession(in *pb.StartCompilationSessionRequest, sessionsDir string, clientInfo *Client) (uint64, *ClientSession) {
+// OpenNewSession creates the session, the priority and the object cache modes are limited by the caller permissions.
+// The client user name is used for logs and stats, the user directory is still taken from the request.
+func (s *Sessions) OpenNewSession(in *pb.StartCompilationSessionRequest, sessionsDir string, clientUserName string, clientInfo *Client, priority pb.CompilationPriority,
+	canReadObjectCache bool, canWriteObjectCache bool, traceContext common.SpanContext) (uint64, *ClientSession) {
 	newSession := &ClientSession{
 		clientUserDir:     "/" + in.ClientUserName + "/",
+		ClientUserName:    clientUserName,
+		SourceFilePath:    in.SourceFilePath,
 		RequiredFilesMeta: make([]requiredFileMetadata, len(in.RequiredFiles)),
 		Compiler:          in.Compiler,
-		UseObjectCache:    in.UseObjectCache,
+		Priority:          priority,
 		ClientInfo:        clientInfo,
+		// StartCompilationSession is the first call of the session
+		activeCalls: 1,
 	}
+	newSession.setObjectCacheMode(in.ObjectCacheMode, in.UseObjectCache)
+	newSession.ReadObjectCache = newSession.ReadObjectCache && canReadObjectCache
+	newSession.WriteObjectCache = newSession.WriteObjectCache && canWriteObjectCache
+	newSession.TraceContext = traceContext
+	newSession.ctx, newSession.cancel = context.WithCancel(context.Background())
+	newSession.CreatedAt = time.Now()
+	newSession.Touch()
 
 	s.mu.Lock()
 	sessionID := s.sessionsCounter
 	s.sessionsCounter++
-	s.sessions[sessionID] = newSession
 	s.mu.Unlock()
 
+	newSession.SessionID = sessionID
+	newSession.Log = common.MakeLogger("session",
+		common.LogField{Key: common.LogFieldSessionID, Value: sessionID},
+		common.LogField{Key: common.LogFieldClientUser, Value: clientUserName},
+		common.LogField{Key: common.LogFieldSource, Value: in.SourceFilePath})
 	newSession.WorkingDir = path.Join(sessionsDir, fmt.Sprint(sessionID))
 
 	for index, meta := range in.RequiredFiles {
@@ -139,6 +376,11 @@ func (s *Sessions) OpenNewSession(in *pb.StartCompilationSessionRequest, session
 
 	newSession.OutObjectFilePath = outFileAbs
 	newSession.compilerArgs = append(in.CompilerArgs, inFileRel, "-o", outFileRel)
+
+	// The session is visible to the status page and the reaper when it is filled completely
+	s.mu.Lock()
+	s.sessions[sessionID] = newSession
+	s.mu.Unlock()
 	return sessionID, newSession
 }
 
@@ -155,6 +397,36 @@ func (s *Sessions) CloseSession(sessionID uint64) {
 	s.mu.Unlock()
 }
 
+// GetIdleSessions returns sessions without client activity for longer than ttl,
+// running compilations and sessions in the middle of a call are not idle.
+func (s *Sessions) GetIdleSessions(ttl time.Duration) []*ClientSession {
+	var idleSessions []*ClientSession
+	s.mu.RLock()
+	for _, session := range s.sessions {
+		state := session.State()
+		if state != SessionStateQueued && state != SessionStateCompiling && session.IdleTime() > ttl && !session.hasActiveCalls() {
+			idleSessions = append(idleSessions, session)
+		}
+	}
+	s.mu.RUnlock()
+	return idleSessions
+}
+
+// GetSessions returns the active sessions from the oldest to the newest.
+func (s *Sessions) GetSessions() []*ClientSession {
+	s.mu.RLock()
+	sessions := make([]*ClientSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+	return sessions
+}
+
 func (s *Sessions) ActiveSessions() int64 {
 	s.mu.RLock()
 	activeSessions := len(s.sessions)
@@ -1,5 +1,7 @@
 package server
 
+import "time"
+
 // Settings ...
 type Settings struct {
 	Port int
@@ -8,9 +10,63 @@ type Settings struct {
 	WorkingDir  string
 	LogFileName string
 	LogSeverity string
+	LogFormat   string
+	LogSink     string
+	LogLevels   string
 
 	SrcCacheLimit int64
 	ObjCacheLimit int64
 
-	StatsdAddress string
+	SrcCacheCompression bool
+	ObjCacheCompression bool
+
+	SrcCacheURL      string
+	ObjCacheURL      string
+	CacheURLLayout   string
+	CacheHTTPTimeout time.Duration
+
+	StatsdAddress  string
+	MetricsAddress string
+	StatusPage     bool
+	Pprof          bool
+
+	TraceDir string
+	TraceURL string
+
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+
+	AuthTokensFile  string
+	AuthHMACKeyFile string
+
+	AllowUnauthenticatedAdmin bool
+
+	AllowedCompilers     []string
+	DeniedCompilerFlags  []string
+	AllowedCompilerFlags []string
+
+	UseSandbox          bool
+	SandboxReadOnlyDirs []string
+	SandboxUID          int
+	SandboxGID          int
+
+	MaxParallelCompilations int
+
+	CompilationTimeout   time.Duration
+	CompilerCPUTimeLimit time.Duration
+	CompilerMemoryLimit  int64
+	CompilerCgroupDir    string
+	CompilerCgroupCPUs   float64
+
+	CompileSourceWaitTimeout time.Duration
+	SessionIdleTTL           time.Duration
+
+	DrainTimeout time.Duration
+
+	Peers             []string
+	AdvertiseAddress  string
+	PeerTimeout       time.Duration
+	PeerTLSCAFile     string
+	PeerAuthTokenFile string
 }
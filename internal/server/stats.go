@@ -3,11 +3,12 @@ package server
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"net"
 	"runtime"
 	"sync/atomic"
 	"time"
+
+	"github.com/AlexK0/popcorn/internal/common"
 )
 
 // AtomicStat ...
@@ -39,133 +40,278 @@ type RPCCallStats struct {
 	Calls          AtomicStat
 	Errors         AtomicStat
 	ProcessingTime AtomicStat
+	Latency        Histogram
 }
 
 type RPCCallObserver struct {
 	start time.Time
 	stat  *RPCCallStats
+	span  *common.Span
 }
 
 func (c *RPCCallStats) StartRPCCall() RPCCallObserver {
 	c.Calls.Increment()
-	return RPCCallObserver{time.Now(), c}
+	return RPCCallObserver{time.Now(), c, nil}
+}
+
+// WithSpan makes the observer finish the span of the call.
+func (o RPCCallObserver) WithSpan(span *common.Span) RPCCallObserver {
+	o.span = span
+	return o
 }
 
 func (o RPCCallObserver) Finish() error {
-	o.stat.ProcessingTime.AddDuration(time.Since(o.start))
+	processingTime := time.Since(o.start)
+	o.stat.ProcessingTime.AddDuration(processingTime)
+	o.stat.Latency.ObserveDuration(processingTime)
+	o.span.End()
 	return nil
 }
 
 func (o RPCCallObserver) FinishWithError(err error) error {
 	o.stat.Errors.Increment()
-	o.stat.ProcessingTime.AddDuration(time.Since(o.start))
+	processingTime := time.Since(o.start)
+	o.stat.ProcessingTime.AddDuration(processingTime)
+	o.stat.Latency.ObserveDuration(processingTime)
+	o.span.SetError(err)
+	o.span.End()
 	return err
 }
 
 type CompilationServerStats struct {
 	TransferredFiles      AtomicStat
 	ForceFileTransferring AtomicStat
+	FilesFromSrcCache     AtomicStat
+
+	ObjCacheHits     AtomicStat
+	ObjCachePeerHits AtomicStat
+	ObjCacheMisses   AtomicStat
+
+	FailedCompilations AtomicStat
+
+	CompilationTimeouts        AtomicStat
+	CompilationMemoryLimitHits AtomicStat
+	CompilationCPULimitHits    AtomicStat
+
+	CancelledCompilations AtomicStat
+	AbandonedSessions     AtomicStat
+	ReapedSessions        AtomicStat
+
+	QueueWaitTime       Histogram
+	CompilerRunTime     Histogram
+	TransferredFileSize Histogram
+	FileTransferTime    Histogram
 
 	StartCompilationSession RPCCallStats
 	TransferFile            RPCCallStats
 	CompileSource           RPCCallStats
 	CloseSession            RPCCallStats
+	GetCachedObject         RPCCallStats
+	PutCachedObject         RPCCallStats
 
 	statsdConnection net.Conn
-	statsBuffer      bytes.Buffer
+	// statsBuffer is used only by SendStats, which is called from the cron goroutine
+	statsBuffer bytes.Buffer
 }
 
+// statsdMaxPacketSize fits into the ethernet MTU
+const statsdMaxPacketSize = 1432
+
 func MakeServerStats(statsdHostPort string) (*CompilationServerStats, error) {
+	stats := &CompilationServerStats{
+		TransferredFileSize: Histogram{unit: histogramBytes},
+	}
 	if len(statsdHostPort) == 0 {
-		return &CompilationServerStats{}, nil
+		return stats, nil
 	}
 
 	conn, err := net.Dial("udp", statsdHostPort)
 	if err != nil {
 		return nil, err
 	}
-	return &CompilationServerStats{
-		statsdConnection: conn,
-	}, nil
+	stats.statsdConnection = conn
+	return stats, nil
 }
 
-func (cs *CompilationServerStats) writeStat(statName string, value int64) {
-	fmt.Fprintf(&cs.statsBuffer, "popcorn.%s:%d|g\n", statName, value)
-}
+// metricKind is the type of the metric for the sinks which distinguish them.
+type metricKind int
 
-func (cs *CompilationServerStats) writeFloatStat(statName string, value float64) {
-	fmt.Fprintf(&cs.statsBuffer, "popcorn.%s:%.9f|g\n", statName, value)
+const (
+	metricGauge metricKind = iota
+	metricCounter
+	metricHistogram
+)
+
+// statsSink receives metrics from collectStats, names are dotted like "caches.obj_cache.count".
+type statsSink interface {
+	writeStat(statName string, kind metricKind, value int64)
+	writeFloatStat(statName string, kind metricKind, value float64)
+	writeRPCCallStat(rpcCallName string, statValue *RPCCallStats)
+	writeHistogram(statName string, histogram *Histogram)
 }
 
-func (cs *CompilationServerStats) writeAtomicStat(statName string, statValue *AtomicStat) {
-	cs.writeStat(statName, statValue.Get())
+// statsdSink formats metrics as statsd gauges.
+type statsdSink struct {
+	buffer *bytes.Buffer
 }
 
-func (cs *CompilationServerStats) writeRPCCallStat(rpcCallName string, statValue *RPCCallStats) {
-	fmt.Fprintf(&cs.statsBuffer, "popcorn.rpc.%s.calls:%d|g\n", rpcCallName, statValue.Calls.Get())
-	fmt.Fprintf(&cs.statsBuffer, "popcorn.rpc.%s.errors:%d|g\n", rpcCallName, statValue.Errors.Get())
-	fmt.Fprintf(&cs.statsBuffer, "popcorn.rpc.%s.processing_time:%.9f|g\n", rpcCallName, statValue.ProcessingTime.GetAsSeconds())
+func (sink statsdSink) writeStat(statName string, kind metricKind, value int64) {
+	fmt.Fprintf(sink.buffer, "popcorn.%s:%d|g\n", statName, value)
 }
 
-func (cs *CompilationServerStats) feedBufferWithStats(compilationServer *CompilationServer) {
-	cs.writeFloatStat("server.uptime", time.Since(compilationServer.StartTime).Seconds())
-	cs.writeStat("server.goroutines", int64(runtime.NumGoroutine()))
+func (sink statsdSink) writeFloatStat(statName string, kind metricKind, value float64) {
+	fmt.Fprintf(sink.buffer, "popcorn.%s:%.9f|g\n", statName, value)
+}
 
-	cs.writeStat("sessions.active", compilationServer.ActiveSessions.ActiveSessions())
+func (sink statsdSink) writeRPCCallStat(rpcCallName string, statValue *RPCCallStats) {
+	fmt.Fprintf(sink.buffer, "popcorn.rpc.%s.calls:%d|g\n", rpcCallName, statValue.Calls.Get())
+	fmt.Fprintf(sink.buffer, "popcorn.rpc.%s.errors:%d|g\n", rpcCallName, statValue.Errors.Get())
+	fmt.Fprintf(sink.buffer, "popcorn.rpc.%s.processing_time:%.9f|g\n", rpcCallName, statValue.ProcessingTime.GetAsSeconds())
+	sink.writeHistogram("rpc."+rpcCallName+".latency", &statValue.Latency)
+}
 
-	cs.writeStat("caches.clients.count", compilationServer.RemoteClients.Count())
-	cs.writeStat("caches.clients.random_client_cache_size", compilationServer.RemoteClients.GetRandomClientCacheSize())
+// writeHistogram sends the samples since the previous flush as timers in milliseconds or histograms in bytes.
+func (sink statsdSink) writeHistogram(statName string, histogram *Histogram) {
+	samples, sampleRate := histogram.takePendingSamples()
+	sampleRateSuffix := ""
+	if sampleRate < 1 {
+		sampleRateSuffix = fmt.Sprintf("|@%.4f", sampleRate)
+	}
+	for _, sample := range samples {
+		if histogram.unit == histogramBytes {
+			fmt.Fprintf(sink.buffer, "popcorn.%s:%d|h%s\n", statName, sample, sampleRateSuffix)
+		} else {
+			fmt.Fprintf(sink.buffer, "popcorn.%s:%.3f|ms%s\n", statName, float64(sample)/float64(time.Millisecond), sampleRateSuffix)
+		}
+	}
+}
 
-	cs.writeStat("caches.system_headers.count", compilationServer.SystemHeaders.GetSystemHeadersCount())
+func writeHTTPCacheStats(sink statsSink, prefix string, cache CacheStorage) {
+	if httpCache, ok := cache.(*HTTPCacheStorage); ok {
+		sink.writeStat(prefix+".hits", metricCounter, httpCache.Hits.Get())
+		sink.writeStat(prefix+".misses", metricCounter, httpCache.Misses.Get())
+		sink.writeStat(prefix+".errors", metricCounter, httpCache.Errors.Get())
+		sink.writeStat(prefix+".uploaded", metricCounter, httpCache.Uploaded.Get())
+	}
+}
 
-	cs.writeStat("caches.src_cache.count", compilationServer.SrcFileCache.GetFilesCount())
-	cs.writeStat("caches.src_cache.purged", compilationServer.SrcFileCache.GetPurgedFiles())
-	cs.writeStat("caches.src_cache.disk_bytes", compilationServer.SrcFileCache.GetBytesOnDisk())
+func writeFileCacheStats(sink statsSink, prefix string, cache CacheStorage) {
+	if fileCache, ok := cache.(*FileCache); ok {
+		originalBytes := fileCache.GetOriginalBytes()
+		sink.writeStat(prefix+".original_bytes", metricGauge, originalBytes)
+		compressionRatio := 1.0
+		if bytesOnDisk := fileCache.GetBytesOnDisk(); bytesOnDisk != 0 {
+			compressionRatio = float64(originalBytes) / float64(bytesOnDisk)
+		}
+		sink.writeFloatStat(prefix+".compression_ratio", metricGauge, compressionRatio)
+	}
+}
 
-	cs.writeStat("caches.obj_cache.count", compilationServer.ObjFileCache.GetFilesCount())
-	cs.writeStat("caches.obj_cache.purged", compilationServer.ObjFileCache.GetPurgedFiles())
-	cs.writeStat("caches.obj_cache.disk_bytes", compilationServer.ObjFileCache.GetBytesOnDisk())
+// collectStats writes all server metrics to the sink.
+func (cs *CompilationServerStats) collectStats(sink statsSink, compilationServer *CompilationServer) {
+	sink.writeFloatStat("server.uptime", metricGauge, time.Since(compilationServer.StartTime).Seconds())
+	sink.writeStat("server.goroutines", metricGauge, int64(runtime.NumGoroutine()))
+
+	sink.writeStat("sessions.active", metricGauge, compilationServer.ActiveSessions.ActiveSessions())
+
+	sink.writeStat("caches.clients.count", metricGauge, compilationServer.RemoteClients.Count())
+	sink.writeStat("caches.clients.random_client_cache_size", metricGauge, compilationServer.RemoteClients.GetRandomClientCacheSize())
+
+	sink.writeStat("caches.system_headers.count", metricGauge, compilationServer.SystemHeaders.GetSystemHeadersCount())
+
+	sink.writeStat("caches.src_cache.count", metricGauge, compilationServer.SrcFileCache.GetFilesCount())
+	sink.writeStat("caches.src_cache.purged", metricCounter, compilationServer.SrcFileCache.GetPurgedFiles())
+	sink.writeStat("caches.src_cache.disk_bytes", metricGauge, compilationServer.SrcFileCache.GetBytesOnDisk())
+
+	sink.writeStat("caches.obj_cache.count", metricGauge, compilationServer.ObjFileCache.GetFilesCount())
+	sink.writeStat("caches.obj_cache.purged", metricCounter, compilationServer.ObjFileCache.GetPurgedFiles())
+	sink.writeStat("caches.obj_cache.disk_bytes", metricGauge, compilationServer.ObjFileCache.GetBytesOnDisk())
+
+	writeFileCacheStats(sink, "caches.src_cache", compilationServer.SrcFileCache)
+	writeFileCacheStats(sink, "caches.obj_cache", compilationServer.ObjFileCache)
+
+	writeHTTPCacheStats(sink, "caches.src_cache.http", compilationServer.SrcFileCache)
+	writeHTTPCacheStats(sink, "caches.obj_cache.http", compilationServer.ObjFileCache)
+
+	sink.writeStat("transferring_files.in_progress", metricGauge, compilationServer.UploadingFiles.TransferringFilesCount())
+	sink.writeStat("transferring_files.received", metricCounter, cs.TransferredFiles.Get())
+	sink.writeStat("transferring_files.force", metricCounter, cs.ForceFileTransferring.Get())
+	sink.writeStat("transferring_files.from_src_cache", metricCounter, cs.FilesFromSrcCache.Get())
+
+	sink.writeStat("compilations.running", metricGauge, compilationServer.CompilationQueue.RunningCompilations())
+	sink.writeStat("compilations.queue.length", metricGauge, compilationServer.CompilationQueue.QueueLength())
+	sink.writeStat("compilations.queue.waited", metricCounter, compilationServer.CompilationQueue.QueuedCompilations.Get())
+	sink.writeFloatStat("compilations.queue.wait_seconds", metricCounter, compilationServer.CompilationQueue.WaitTime.GetAsSeconds())
+
+	sink.writeStat("compilations.limits.timeouts", metricCounter, cs.CompilationTimeouts.Get())
+	sink.writeStat("compilations.limits.memory", metricCounter, cs.CompilationMemoryLimitHits.Get())
+	sink.writeStat("compilations.limits.cpu_time", metricCounter, cs.CompilationCPULimitHits.Get())
+
+	sink.writeHistogram("compilations.queue.wait_duration", &cs.QueueWaitTime)
+	sink.writeHistogram("compilations.compiler.duration", &cs.CompilerRunTime)
+	sink.writeHistogram("transferring_files.size", &cs.TransferredFileSize)
+	sink.writeHistogram("transferring_files.duration", &cs.FileTransferTime)
+
+	sink.writeStat("compilations.obj_cache.hits", metricCounter, cs.ObjCacheHits.Get())
+	sink.writeStat("compilations.obj_cache.peer_hits", metricCounter, cs.ObjCachePeerHits.Get())
+	sink.writeStat("compilations.obj_cache.misses", metricCounter, cs.ObjCacheMisses.Get())
+	sink.writeStat("compilations.failed", metricCounter, cs.FailedCompilations.Get())
+	sink.writeStat("compilations.cancelled", metricCounter, cs.CancelledCompilations.Get())
+	sink.writeStat("sessions.abandoned", metricCounter, cs.AbandonedSessions.Get())
+	sink.writeStat("sessions.reaped", metricCounter, cs.ReapedSessions.Get())
+
+	if peers := compilationServer.ObjectCachePeers; peers != nil {
+		sink.writeStat("peers.obj_cache.hits", metricCounter, peers.Hits.Get())
+		sink.writeStat("peers.obj_cache.misses", metricCounter, peers.Misses.Get())
+		sink.writeStat("peers.obj_cache.errors", metricCounter, peers.Errors.Get())
+		sink.writeFloatStat("peers.obj_cache.fetch_time", metricCounter, peers.FetchTime.GetAsSeconds())
+		sink.writeStat("peers.obj_cache.pushes", metricCounter, peers.Pushes.Get())
+		sink.writeStat("peers.obj_cache.push_errors", metricCounter, peers.PushErrors.Get())
+		sink.writeStat("peers.obj_cache.pushes_dropped", metricCounter, peers.PushesDropped.Get())
+	}
 
-	cs.writeStat("transferring_files.in_progress", compilationServer.UploadingFiles.TransferringFilesCount())
-	cs.writeAtomicStat("transferring_files.received", &cs.TransferredFiles)
-	cs.writeAtomicStat("transferring_files.force", &cs.ForceFileTransferring)
+	if compilationServer.Authenticator != nil {
+		sink.writeStat("auth.rejected", metricCounter, compilationServer.Authenticator.Rejected.Get())
+	}
 
-	cs.writeRPCCallStat("start_compilation_session", &cs.StartCompilationSession)
-	cs.writeRPCCallStat("transfer_file", &cs.TransferFile)
-	cs.writeRPCCallStat("compile_source", &cs.CompileSource)
-	cs.writeRPCCallStat("close_session", &cs.CloseSession)
+	sink.writeRPCCallStat("start_compilation_session", &cs.StartCompilationSession)
+	sink.writeRPCCallStat("transfer_file", &cs.TransferFile)
+	sink.writeRPCCallStat("compile_source", &cs.CompileSource)
+	sink.writeRPCCallStat("close_session", &cs.CloseSession)
+	sink.writeRPCCallStat("get_cached_object", &cs.GetCachedObject)
+	sink.writeRPCCallStat("put_cached_object", &cs.PutCachedObject)
 
 	var mem runtime.MemStats
 	runtime.ReadMemStats(&mem)
 
-	cs.writeStat("memory.alloc", int64(mem.Alloc))
-	cs.writeStat("memory.total_alloc", int64(mem.TotalAlloc))
-	cs.writeStat("memory.sys", int64(mem.Sys))
-	cs.writeStat("memory.lookups", int64(mem.Lookups))
-	cs.writeStat("memory.mallocs", int64(mem.Mallocs))
-	cs.writeStat("memory.frees", int64(mem.Frees))
-	cs.writeStat("memory.heap_alloc", int64(mem.HeapAlloc))
-	cs.writeStat("memory.heap_sys", int64(mem.HeapSys))
-	cs.writeStat("memory.heap_idle", int64(mem.HeapIdle))
-	cs.writeStat("memory.heap_inuse", int64(mem.HeapInuse))
-	cs.writeStat("memory.heap_released", int64(mem.HeapReleased))
-	cs.writeStat("memory.heap_objects", int64(mem.HeapObjects))
-	cs.writeStat("memory.stack_inuse", int64(mem.StackInuse))
-	cs.writeStat("memory.stack_sys", int64(mem.StackSys))
-	cs.writeStat("memory.mspan_inuse", int64(mem.MSpanInuse))
-	cs.writeStat("memory.mspan_sys", int64(mem.MSpanSys))
-	cs.writeStat("memory.mcache_inuse", int64(mem.MCacheInuse))
-	cs.writeStat("memory.mcache_sys", int64(mem.MCacheSys))
-	cs.writeStat("memory.buck_hash_sys", int64(mem.BuckHashSys))
-	cs.writeStat("memory.gc_sys", int64(mem.GCSys))
-	cs.writeStat("memory.other_sys", int64(mem.OtherSys))
-
-	cs.writeStat("gc.next", int64(mem.NextGC))
-	cs.writeStat("gc.last", int64(mem.LastGC))
-	cs.writeStat("gc.cycles", int64(mem.NumGC))
-	cs.writeStat("gc.forced_cycles", int64(mem.NumForcedGC))
-	cs.writeFloatStat("gc.pause_total", time.Duration(mem.PauseTotalNs).Seconds())
-	cs.writeFloatStat("gc.cpu_fraction", mem.GCCPUFraction)
+	sink.writeStat("memory.alloc", metricGauge, int64(mem.Alloc))
+	sink.writeStat("memory.total_alloc", metricCounter, int64(mem.TotalAlloc))
+	sink.writeStat("memory.sys", metricGauge, int64(mem.Sys))
+	sink.writeStat("memory.lookups", metricCounter, int64(mem.Lookups))
+	sink.writeStat("memory.mallocs", metricCounter, int64(mem.Mallocs))
+	sink.writeStat("memory.frees", metricCounter, int64(mem.Frees))
+	sink.writeStat("memory.heap_alloc", metricGauge, int64(mem.HeapAlloc))
+	sink.writeStat("memory.heap_sys", metricGauge, int64(mem.HeapSys))
+	sink.writeStat("memory.heap_idle", metricGauge, int64(mem.HeapIdle))
+	sink.writeStat("memory.heap_inuse", metricGauge, int64(mem.HeapInuse))
+	sink.writeStat("memory.heap_released", metricGauge, int64(mem.HeapReleased))
+	sink.writeStat("memory.heap_objects", metricGauge, int64(mem.HeapObjects))
+	sink.writeStat("memory.stack_inuse", metricGauge, int64(mem.StackInuse))
+	sink.writeStat("memory.stack_sys", metricGauge, int64(mem.StackSys))
+	sink.writeStat("memory.mspan_inuse", metricGauge, int64(mem.MSpanInuse))
+	sink.writeStat("memory.mspan_sys", metricGauge, int64(mem.MSpanSys))
+	sink.writeStat("memory.mcache_inuse", metricGauge, int64(mem.MCacheInuse))
+	sink.writeStat("memory.mcache_sys", metricGauge, int64(mem.MCacheSys))
+	sink.writeStat("memory.buck_hash_sys", metricGauge, int64(mem.BuckHashSys))
+	sink.writeStat("memory.gc_sys", metricGauge, int64(mem.GCSys))
+	sink.writeStat("memory.other_sys", metricGauge, int64(mem.OtherSys))
+
+	sink.writeStat("gc.next", metricGauge, int64(mem.NextGC))
+	sink.writeStat("gc.last", metricGauge, int64(mem.LastGC))
+	sink.writeStat("gc.cycles", metricCounter, int64(mem.NumGC))
+	sink.writeStat("gc.forced_cycles", metricCounter, int64(mem.NumForcedGC))
+	sink.writeFloatStat("gc.pause_total", metricCounter, time.Duration(mem.PauseTotalNs).Seconds())
+	sink.writeFloatStat("gc.cpu_fraction", metricGauge, mem.GCCPUFraction)
 }
 
 func (cs *CompilationServerStats) SendStats(compilationServer *CompilationServer) {
@@ -173,17 +319,21 @@ func (cs *CompilationServerStats) SendStats(compilationServer *CompilationServer
 		return
 	}
 
-	cs.feedBufferWithStats(compilationServer)
-
-	_, _ = io.Copy(cs.statsdConnection, &cs.statsBuffer)
-	cs.statsBuffer.Reset()
-}
-
-func (cs *CompilationServerStats) GetStatsRawBytes(compilationServer *CompilationServer) []byte {
-	cs.feedBufferWithStats(compilationServer)
-	result := cs.statsBuffer.Bytes()
+	cs.collectStats(statsdSink{&cs.statsBuffer}, compilationServer)
+
+	// The histogram samples may make the buffer too large for one datagram, it is split by lines
+	stats := cs.statsBuffer.Bytes()
+	for len(stats) != 0 {
+		packetSize := len(stats)
+		if packetSize > statsdMaxPacketSize {
+			if lineEnd := bytes.LastIndexByte(stats[:statsdMaxPacketSize], '\n'); lineEnd != -1 {
+				packetSize = lineEnd + 1
+			}
+		}
+		_, _ = cs.statsdConnection.Write(stats[:packetSize])
+		stats = stats[packetSize:]
+	}
 	cs.statsBuffer.Reset()
-	return result
 }
 
 func (cs *CompilationServerStats) Close() {
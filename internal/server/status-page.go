@@ -0,0 +1,242 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	pb "github.com/AlexK0/popcorn/internal/api/proto/v1"
+	"github.com/AlexK0/popcorn/internal/common"
+)
+
+const statusPageTopClients = 10
+
+// StatusSession is an active session on the status page.
+type StatusSession struct {
+	SessionID      uint64  `json:"session_id"`
+	SourceFilePath string  `json:"source_file"`
+	Compiler       string  `json:"compiler"`
+	ClientUserName string  `json:"client_user"`
+	State          string  `json:"state"`
+	Elapsed        float64 `json:"elapsed_seconds"`
+}
+
+// StatusCache is a cache on the status page, the hit rate is a fraction of lookups.
+type StatusCache struct {
+	Name          string  `json:"name"`
+	Remote        bool    `json:"remote"`
+	FilesCount    int64   `json:"files_count"`
+	BytesOnDisk   int64   `json:"bytes_on_disk"`
+	OriginalBytes int64   `json:"original_bytes,omitempty"`
+	SizeLimit     int64   `json:"size_limit,omitempty"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+// StatusClient is a client on the status page.
+type StatusClient struct {
+	UserName     string    `json:"user"`
+	Address      string    `json:"address"`
+	Compilations int64     `json:"compilations"`
+	CompilerTime float64   `json:"compiler_time_seconds"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// StatusFailure is a recent compilation failure on the status page.
+type StatusFailure struct {
+	Time           time.Time `json:"time"`
+	SessionID      uint64    `json:"session_id"`
+	SourceFilePath string    `json:"source_file"`
+	Compiler       string    `json:"compiler"`
+	ClientUserName string    `json:"client_user"`
+	ExitCode       int       `json:"exit_code"`
+	Error          string    `json:"error,omitempty"`
+	Output         string    `json:"output,omitempty"`
+}
+
+// ServerStatus is the content of the status page and its json equivalent.
+type ServerStatus struct {
+	ServerName string    `json:"server_name"`
+	Version    string    `json:"version"`
+	StartTime  time.Time `json:"start_time"`
+	Uptime     float64   `json:"uptime_seconds"`
+	Draining   bool      `json:"draining"`
+
+	RunningCompilations     int64 `json:"running_compilations"`
+	QueuedCompilations      int64 `json:"queued_compilations"`
+	MaxParallelCompilations int64 `json:"max_parallel_compilations"`
+
+	Sessions       []StatusSession `json:"sessions"`
+	Caches         []StatusCache   `json:"caches"`
+	TopClients     []StatusClient  `json:"top_clients"`
+	RecentFailures []StatusFailure `json:"recent_failures"`
+}
+
+func sessionStateName(state int32) string {
+	switch state {
+	case SessionStateUploading:
+		return "uploading"
+	case SessionStateQueued:
+		return "queued"
+	case SessionStateCompiling:
+		return "compiling"
+	case SessionStateCompiled:
+		return "compiled"
+	}
+	return "unknown"
+}
+
+func makeStatusCache(name string, cache CacheStorage, hits int64, misses int64) StatusCache {
+	statusCache := StatusCache{
+		Name:        name,
+		FilesCount:  cache.GetFilesCount(),
+		BytesOnDisk: cache.GetBytesOnDisk(),
+		Hits:        hits,
+		Misses:      misses,
+	}
+	if hits+misses != 0 {
+		statusCache.HitRate = float64(hits) / float64(hits+misses)
+	}
+	if fileCache, ok := cache.(*FileCache); ok {
+		statusCache.OriginalBytes = fileCache.GetOriginalBytes()
+		statusCache.SizeLimit = fileCache.GetSizeLimit()
+	} else {
+		statusCache.Remote = true
+	}
+	return statusCache
+}
+
+// GetServerStatus collects the state of the server for the status page.
+func (s *CompilationServer) GetServerStatus() *ServerStatus {
+	now := time.Now()
+	serverStatus := &ServerStatus{
+		ServerName: s.ServerName,
+		Version:    common.GetVersion(),
+		StartTime:  s.StartTime,
+		Uptime:     now.Sub(s.StartTime).Seconds(),
+		Draining:   s.IsDraining(),
+
+		RunningCompilations:     s.CompilationQueue.RunningCompilations(),
+		QueuedCompilations:      s.CompilationQueue.QueueLength(),
+		MaxParallelCompilations: s.CompilationQueue.MaxRunningCompilations(),
+
+		Sessions:       []StatusSession{},
+		TopClients:     []StatusClient{},
+		RecentFailures: []StatusFailure{},
+	}
+
+	for _, session := range s.ActiveSessions.GetSessions() {
+		serverStatus.Sessions = append(serverStatus.Sessions, StatusSession{
+			SessionID:      session.SessionID,
+			SourceFilePath: session.SourceFilePath,
+			Compiler:       session.Compiler,
+			ClientUserName: session.ClientUserName,
+			State:          sessionStateName(session.State()),
+			Elapsed:        now.Sub(session.CreatedAt).Seconds(),
+		})
+	}
+
+	srcHits, srcMisses := s.getCacheHitsAndMisses(pb.CacheType_CACHE_SRC)
+	objHits, objMisses := s.getCacheHitsAndMisses(pb.CacheType_CACHE_OBJ)
+	serverStatus.Caches = []StatusCache{
+		makeStatusCache("src", s.SrcFileCache, srcHits, srcMisses),
+		makeStatusCache("obj", s.ObjFileCache, objHits, objMisses),
+	}
+
+	for _, client := range s.RemoteClients.GetTopClients(statusPageTopClients) {
+		serverStatus.TopClients = append(serverStatus.TopClients, StatusClient{
+			UserName:     client.UserName,
+			Address:      client.Address,
+			Compilations: client.Compilations.Get(),
+			CompilerTime: client.CompilerTime.GetAsSeconds(),
+			LastSeen:     client.LastSeen(),
+		})
+	}
+
+	for _, failure := range s.RecentFailures.GetRecent() {
+		serverStatus.RecentFailures = append(serverStatus.RecentFailures, StatusFailure(failure))
+	}
+	return serverStatus
+}
+
+// ServeStatusJSON ...
+func (s *CompilationServer) ServeStatusJSON(w http.ResponseWriter, r *http.Request) {
+	data, err := json.MarshalIndent(s.GetServerStatus(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"seconds": func(seconds float64) string {
+		return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+	},
+	"percent": func(fraction float64) string {
+		return fmt.Sprintf("%.1f%%", fraction*100)
+	},
+	"time": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="5">
+<title>popcorn-server {{.ServerName}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 20px; }
+table { border-collapse: collapse; margin-bottom: 20px; }
+th, td { border: 1px solid #ccc; padding: 3px 8px; text-align: left; vertical-align: top; }
+th { background: #eee; }
+pre { margin: 0; white-space: pre-wrap; max-width: 800px; }
+</style>
+</head>
+<body>
+<h2>popcorn-server {{.ServerName}}{{if .Draining}} (draining){{end}}</h2>
+<p>Version {{.Version}}, started {{time .StartTime}}, uptime {{seconds .Uptime}}. <a href="status.json">json</a></p>
+<p>Compilations: {{.RunningCompilations}} running of {{.MaxParallelCompilations}}, {{.QueuedCompilations}} queued.</p>
+
+<h3>Active sessions ({{len .Sessions}})</h3>
+<table>
+<tr><th>Session</th><th>Source</th><th>Compiler</th><th>Client user</th><th>State</th><th>Elapsed</th></tr>
+{{range .Sessions}}<tr><td>{{.SessionID}}</td><td>{{.SourceFilePath}}</td><td>{{.Compiler}}</td><td>{{.ClientUserName}}</td><td>{{.State}}</td><td>{{seconds .Elapsed}}</td></tr>
+{{end}}</table>
+
+<h3>Caches</h3>
+<table>
+<tr><th>Cache</th><th>Files</th><th>Bytes on disk</th><th>Original bytes</th><th>Size limit</th><th>Hits</th><th>Misses</th><th>Hit rate</th></tr>
+{{range .Caches}}<tr><td>{{.Name}}{{if .Remote}} (remote){{end}}</td><td>{{.FilesCount}}</td><td>{{.BytesOnDisk}}</td><td>{{.OriginalBytes}}</td><td>{{.SizeLimit}}</td><td>{{.Hits}}</td><td>{{.Misses}}</td><td>{{percent .HitRate}}</td></tr>
+{{end}}</table>
+
+<h3>Top clients</h3>
+<table>
+<tr><th>User</th><th>Address</th><th>Compilations</th><th>Compiler time</th><th>Last seen</th></tr>
+{{range .TopClients}}<tr><td>{{.UserName}}</td><td>{{.Address}}</td><td>{{.Compilations}}</td><td>{{seconds .CompilerTime}}</td><td>{{time .LastSeen}}</td></tr>
+{{end}}</table>
+
+<h3>Recent failures</h3>
+<table>
+<tr><th>Time</th><th>Session</th><th>Source</th><th>Compiler</th><th>Client user</th><th>Exit code</th><th>Error</th></tr>
+{{range .RecentFailures}}<tr><td>{{time .Time}}</td><td>{{.SessionID}}</td><td>{{.SourceFilePath}}</td><td>{{.Compiler}}</td><td>{{.ClientUserName}}</td><td>{{.ExitCode}}</td><td>{{.Error}}<pre>{{.Output}}</pre></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// ServeStatusPage ...
+func (s *CompilationServer) ServeStatusPage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPageTemplate.Execute(w, s.GetServerStatus()); err != nil {
+		common.LogError("Can't render status page:", err)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/AlexK0/popcorn/internal/common"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// MakeTransportCredentials ...
+func MakeTransportCredentials(certFile string, keyFile string, clientCAFile string) (credentials.TransportCredentials, error) {
+	if len(certFile) == 0 && len(keyFile) == 0 {
+		if len(clientCAFile) != 0 {
+			return nil, fmt.Errorf("Client certificate verification requires TLS certificate and key")
+		}
+		return nil, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Can't load TLS certificate: %v", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.NoClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(clientCAFile) != 0 {
+		caPEM, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read client CA: %v", err)
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("No certificates found in client CA %q", clientCAFile)
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// getVerifiedClientCertificate returns the leaf certificate of the client, if it was verified on TLS handshake.
+func getVerifiedClientCertificate(ctx context.Context) *x509.Certificate {
+	clientPeer, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := clientPeer.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// getClientID prefers the verified client certificate over the client id from the request, which can be spoofed.
+func getClientID(ctx context.Context, requestClientID common.SHA256Struct) common.SHA256Struct {
+	if certificate := getVerifiedClientCertificate(ctx); certificate != nil {
+		return common.MakeSHA256StructFromArray(sha256.Sum256(certificate.Raw))
+	}
+	return requestClientID
+}
+
+// getClientUserName prefers the verified client certificate or the authentication token over the user name from the request,
+// the name from the request is marked as claimed by the client.
+func getClientUserName(ctx context.Context, requestUserName string) string {
+	if certificate := getVerifiedClientCertificate(ctx); certificate != nil && len(certificate.Subject.CommonName) != 0 {
+		return certificate.Subject.CommonName
+	}
+	if token := getAuthToken(ctx); token != nil && len(token.Name) != 0 {
+		return token.Name
+	}
+	return requestUserName + " (claimed)"
+}
+
+// getClientAddress returns the host of the client connection, it is empty if it is unknown.
+func getClientAddress(ctx context.Context) string {
+	clientPeer, ok := peer.FromContext(ctx)
+	if !ok || clientPeer.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(clientPeer.Addr.String())
+	if err != nil {
+		return clientPeer.Addr.String()
+	}
+	return host
+}